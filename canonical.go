@@ -0,0 +1,135 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Serializes a value as canonical JSON according to the JSON Canonicalization
+// Scheme of RFC 8785, so that equal values always produce identical bytes,
+// which makes the output suitable for hashing and signing.
+//
+// The output has no extra whitespace, strings use the minimal escaping of
+// Marshal, and object members are sorted by their keys compared as UTF-16
+// code units. If an object contains duplicate keys, only the last value for
+// that key is kept, the same as in AsObject. Numbers are written in the
+// shortest form used by ECMAScript, which means integers are treated as
+// IEEE 754 doubles and may lose precision beyond 2^53, and the original
+// formatting of parsed numbers is not preserved.
+//
+// Returns ErrRange if a number is NaN, infinite, or too large to be a double,
+// and ErrType if the value or any of its children has an unknown type.
+func MarshalCanonical(v *Value) ([]byte, error) {
+	e := &encodeState{canonical: true}
+	if err := e.marshal(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// Removes duplicate keys, keeping the last value, and sorts the members
+// by their keys as UTF-16 code units.
+func canonicalPairs(pairs []pair) []pair {
+	sorted := make([]pair, 0, len(pairs))
+	seen := map[string]int{}
+	for _, p := range pairs {
+		if i, ok := seen[p.key]; ok {
+			sorted[i].val = p.val
+			continue
+		}
+		seen[p.key] = len(sorted)
+		sorted = append(sorted, p)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lessUTF16(sorted[i].key, sorted[j].key)
+	})
+	return sorted
+}
+
+// Compares two strings by their UTF-16 code units. This differs from
+// comparing them by bytes for characters outside the Basic Multilingual
+// Plane, which are encoded as surrogates.
+func lessUTF16(a, b string) bool {
+	x := utf16.Encode([]rune(a))
+	y := utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// Writes a number as an IEEE 754 double in canonical form.
+func (e *encodeState) writeCanonicalNumber(v *Value) error {
+	var f float64
+	switch {
+	case v.jsonType == Number:
+		f = v.numberValue
+	case v.bigValue != nil:
+		f, _ = new(big.Float).SetInt(v.bigValue).Float64()
+	default:
+		f = float64(v.integerValue)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%w: cannot canonicalize number %v", ErrRange, v)
+	}
+	e.WriteString(formatECMAScript(f))
+	return nil
+}
+
+// Formats a finite double the way ECMAScript's Number.prototype.toString
+// does, as required by RFC 8785. Both use the shortest digits that round-trip,
+// but ECMAScript switches to exponential notation at different magnitudes
+// and writes exponents differently than strconv.
+func formatECMAScript(f float64) string {
+	if f == 0 {
+		// Also covers negative zero
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// Split the shortest representation d.ddde±xx into its digits and
+	// the position of the decimal point relative to them.
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	k := len(digits)
+	n := x + 1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	if k == 1 {
+		return sign + digits + "e" + expSign + strconv.Itoa(abs(n-1))
+	}
+	return sign + digits[:1] + "." + digits[1:] + "e" + expSign + strconv.Itoa(abs(n-1))
+}
+
+// Gets the absolute value of an int.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,102 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{`null`, `null`},
+		{` [ true , false ] `, `[true,false]`},
+		{`{ "b": 1, "a": { "d": [], "c": {} } }`, `{"a":{"c":{},"d":[]},"b":1}`},
+		{`{"a": 1, "b": 2, "a": 3}`, `{"a":3,"b":2}`},
+		{
+			`{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			`{"€": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "😀": "Emoji: Grinning Face", "\u0080": "Control", "ö": "Latin Small Letter O With Diaeresis"}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{`[1.0, -0.0, 100, 1e2, 12345678901234567890]`, `[1,0,100,100,12345678901234567000]`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			v, err := ParseString(test.input)
+			if err != nil {
+				t.Fatalf("expected %v got %v", nil, err)
+			}
+			actual, err := MarshalCanonical(v)
+			if err != nil {
+				t.Errorf("expected %v got %v", nil, err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %v got %v", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestMarshalCanonicalInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    *Value
+		expected error
+	}{
+		{"NaN", NewNumber(math.NaN()), ErrRange},
+		{"Inf", NewArray(NewNumber(math.Inf(-1))), ErrRange},
+		{"huge", &Value{jsonType: Integer, bigValue: new(big.Int).Exp(big.NewInt(10), big.NewInt(400), nil)}, ErrRange},
+		{"unknown", &Value{jsonType: numTypes}, ErrType},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := MarshalCanonical(test.input)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestFormatECMAScript(t *testing.T) {
+	// Test vectors from RFC 8785 Appendix B
+	for _, test := range []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if actual := formatECMAScript(math.Float64frombits(test.bits)); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package json
+
+// The category of a character, as used by the parser to decide which
+// characters can come next. Letters that have no special meaning in JSON
+// are in ClassOther, as are all characters outside of ASCII.
+type CharClass int8
+
+// Possible character classes
+const (
+	ClassSpace        = CharClass(charSpace) // space
+	ClassNewline      = CharClass(charLF___) // line feed
+	ClassWhitespace   = CharClass(charWhite) // tab and carriage return
+	ClassLeftBrace    = CharClass(charLCurB) // {
+	ClassRightBrace   = CharClass(charRCurB) // }
+	ClassLeftBracket  = CharClass(charLSqrB) // [
+	ClassRightBracket = CharClass(charRSqrB) // ]
+	ClassColon        = CharClass(charColon) // :
+	ClassComma        = CharClass(charComma) // ,
+	ClassQuote        = CharClass(charQuote) // "
+	ClassBackslash    = CharClass(charBacks) // \
+	ClassSlash        = CharClass(charSlash) // /
+	ClassStar         = CharClass(charStar_) // *
+	ClassPlus         = CharClass(charPlus_) // +
+	ClassMinus        = CharClass(charMinus) // -
+	ClassPoint        = CharClass(charPoint) // .
+	ClassZero         = CharClass(charZero_) // 0
+	ClassDigit        = CharClass(charDigit) // 123456789
+	ClassLowerA       = CharClass(charLow_A) // a
+	ClassLowerB       = CharClass(charLow_B) // b
+	ClassLowerC       = CharClass(charLow_C) // c
+	ClassLowerD       = CharClass(charLow_D) // d
+	ClassLowerE       = CharClass(charLow_E) // e
+	ClassLowerF       = CharClass(charLow_F) // f
+	ClassLowerL       = CharClass(charLow_L) // l
+	ClassLowerN       = CharClass(charLow_N) // n
+	ClassLowerR       = CharClass(charLow_R) // r
+	ClassLowerS       = CharClass(charLow_S) // s
+	ClassLowerT       = CharClass(charLow_T) // t
+	ClassLowerU       = CharClass(charLow_U) // u
+	ClassUpperHex     = CharClass(charABCDF) // ABCDF
+	ClassUpperE       = CharClass(charCap_E) // E
+	ClassOther        = CharClass(charEtc__) // everything else
+	ClassEOF          = CharClass(charEof__) // end of input
+	ClassInvalid      = CharClass(_________) // control characters not allowed anywhere
+)
+
+var charClassStrings = [numClasses]string{
+	"<space>",
+	"<newline>",
+	"<whitespace>",
+	"{",
+	"}",
+	"[",
+	"]",
+	":",
+	",",
+	`"`,
+	`\`,
+	"/",
+	"*",
+	"+",
+	"-",
+	".",
+	"0",
+	"<digit>",
+	"a",
+	"b",
+	"c",
+	"d",
+	"e",
+	"f",
+	"l",
+	"n",
+	"r",
+	"s",
+	"t",
+	"u",
+	"<hex letter>",
+	"E",
+	"<other>",
+	"<eof>",
+}
+
+// Returns a string representation of a character class.
+func (c CharClass) String() string {
+	if c == ClassInvalid {
+		return "<invalid>"
+	}
+	if c < 0 || c >= CharClass(numClasses) {
+		return "<unknown>"
+	}
+	return charClassStrings[c]
+}
+
+// Gets the class the parser puts a character in. A negative rune stands for
+// the end of input and is in ClassEOF.
+func Classify(r rune) CharClass {
+	return CharClass(classify(r))
+}
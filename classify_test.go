@@ -0,0 +1,60 @@
+package json
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	for _, test := range []struct {
+		input    rune
+		expected CharClass
+	}{
+		{' ', ClassSpace},
+		{'\n', ClassNewline},
+		{'\t', ClassWhitespace},
+		{'\r', ClassWhitespace},
+		{'{', ClassLeftBrace},
+		{']', ClassRightBracket},
+		{'"', ClassQuote},
+		{'\\', ClassBackslash},
+		{'0', ClassZero},
+		{'7', ClassDigit},
+		{'e', ClassLowerE},
+		{'E', ClassUpperE},
+		{'B', ClassUpperHex},
+		{'x', ClassOther},
+		{'~', ClassOther},
+		{0x7f, ClassOther},
+		{0x80, ClassOther},
+		{'世', ClassOther},
+		{0, ClassInvalid},
+		{'\v', ClassInvalid},
+		{-1, ClassEOF},
+	} {
+		t.Run(test.expected.String(), func(t *testing.T) {
+			if actual := Classify(test.input); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCharClassString(t *testing.T) {
+	for _, test := range []struct {
+		input    CharClass
+		expected string
+	}{
+		{ClassSpace, "<space>"},
+		{ClassQuote, `"`},
+		{ClassLowerU, "u"},
+		{ClassUpperHex, "<hex letter>"},
+		{ClassEOF, "<eof>"},
+		{ClassInvalid, "<invalid>"},
+		{CharClass(numClasses), "<unknown>"},
+		{-2, "<unknown>"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if actual := test.input.String(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
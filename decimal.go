@@ -0,0 +1,145 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The largest exponent accepted in a number with ExactDecimals set. Working
+// out a decimal takes time and memory in proportion to its exponent, so
+// without a limit a short literal like 1e-99999999 could be very costly.
+const maxDecimalExponent = 1000
+
+// An exact decimal number, such as an amount of money. Unlike a float64, it
+// holds numbers like 0.1 exactly, and adding, subtracting or multiplying
+// decimals gives an exact result, so 0.1 + 0.2 is exactly 0.3. Decimals are
+// immutable, and the zero value is 0.
+type Decimal struct {
+	rat *big.Rat
+}
+
+// Parses a decimal from a JSON number, such as "12.50" or "-1.5e3".
+// Returns ErrParse if the string is not a valid JSON number.
+func ParseDecimal(s string) (Decimal, error) {
+	v, err := parseBytes([]byte(s), ParseOptions{ExactDecimals: true})
+	if err != nil {
+		return Decimal{}, err
+	}
+	if v.jsonType != Integer && v.jsonType != Number {
+		return Decimal{}, fmt.Errorf("%w: %q is not a number", ErrParse, s)
+	}
+	return v.AsDecimal()
+}
+
+// Creates a number value that holds a decimal exactly, so that it is
+// marshaled with all of its digits.
+func NewDecimal(d Decimal) *Value {
+	raw := d.String()
+	if !strings.Contains(raw, ".") {
+		// Keep it a Number when it's parsed back
+		raw += ".0"
+	}
+	return &Value{jsonType: Number, numberValue: d.Float64(), rawNumber: raw, decimalValue: d.value()}
+}
+
+// Converts a number literal to an exact fraction. Fails if its exponent is
+// too large.
+func parseDecimal(s string) (*big.Rat, error) {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return nil, fmt.Errorf("number %s out of range", s)
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %s", s)
+	}
+	return r, nil
+}
+
+// Gets the fraction behind a decimal, treating the zero value as 0.
+func (d Decimal) value() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return d.rat
+}
+
+// Adds two decimals.
+func (d Decimal) Add(x Decimal) Decimal {
+	return Decimal{new(big.Rat).Add(d.value(), x.value())}
+}
+
+// Subtracts x from the decimal.
+func (d Decimal) Sub(x Decimal) Decimal {
+	return Decimal{new(big.Rat).Sub(d.value(), x.value())}
+}
+
+// Multiplies two decimals.
+func (d Decimal) Mul(x Decimal) Decimal {
+	return Decimal{new(big.Rat).Mul(d.value(), x.value())}
+}
+
+// Compares two decimals, returning -1 if d < x, 0 if d == x, and 1 if d > x.
+func (d Decimal) Cmp(x Decimal) int {
+	return d.value().Cmp(x.value())
+}
+
+// Gets a copy of the decimal as a fraction, such as for dividing it.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).Set(d.value())
+}
+
+// Gets the float64 nearest to the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.value().Float64()
+	return f
+}
+
+// Writes the decimal out in full with no exponent, such as "12.5" or "-0.003".
+func (d Decimal) String() string {
+	r := d.value()
+	// A decimal's denominator only has factors of 2 and 5, and it takes as
+	// many digits after the point as the larger count of the two.
+	denom := new(big.Int).Set(r.Denom())
+	twos := int(denom.TrailingZeroBits())
+	fives := 0
+	five, mod := big.NewInt(5), new(big.Int)
+	for {
+		q, m := new(big.Int).QuoRem(denom, five, mod)
+		if m.Sign() != 0 {
+			break
+		}
+		denom = q
+		fives++
+	}
+	if twos < fives {
+		twos = fives
+	}
+	return r.FloatString(twos)
+}
+
+// Extracts a number from the JSON as an exact decimal. Integers are always
+// exact, as are Numbers that were parsed with ExactDecimals set or created
+// with NewDecimal. Other Numbers only have the binary value of their float64,
+// which can have more digits than the number was written with: 0.1 becomes
+// 0.1000000000000000055511151231257827021181583404541015625.
+// Returns ErrType if the value is neither a number nor an integer, and
+// ErrRange if it is NaN or infinite.
+func (v *Value) AsDecimal() (Decimal, error) {
+	switch {
+	case v.jsonType == Integer:
+		return Decimal{new(big.Rat).SetInt(v.bigInt())}, nil
+	case v.jsonType != Number:
+		return Decimal{}, v.typeError(Number, Integer)
+	case v.decimalValue != nil:
+		return Decimal{v.decimalValue}, nil
+	case math.IsNaN(v.numberValue) || math.IsInf(v.numberValue, 0):
+		return Decimal{}, fmt.Errorf("%w: %v is not a decimal", ErrRange, v.numberValue)
+	}
+	return Decimal{new(big.Rat).SetFloat64(v.numberValue)}, nil
+}
@@ -0,0 +1,137 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAsDecimal(t *testing.T) {
+	exact := ParseOptions{ExactDecimals: true}
+	for _, test := range []struct {
+		input    string
+		options  ParseOptions
+		expected string
+	}{
+		{`0.1`, exact, "0.1"},
+		{`-12.50`, exact, "-12.5"},
+		{`1.5e3`, exact, "1500"},
+		{`25E-4`, exact, "0.0025"},
+		{`1e-400`, exact, "0." + strings.Repeat("0", 399) + "1"},
+		{`42`, exact, "42"},
+		{`123456789012345678901234567890`, ParseOptions{}, "123456789012345678901234567890"},
+		{`0.5`, ParseOptions{}, "0.5"},
+		{`0.1`, ParseOptions{}, "0.1000000000000000055511151231257827021181583404541015625"},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val, err := ParseWithOptions(strings.NewReader(test.input), test.options)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			d, err := val.AsDecimal()
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if actual := d.String(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		input *Value
+		err   error
+	}{
+		{NewString("1"), ErrType},
+		{NewNull(), ErrType},
+		{NewNumber(math.NaN()), ErrRange},
+		{NewNumber(math.Inf(-1)), ErrRange},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			if _, err := test.input.AsDecimal(); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestParseExactDecimalsRange(t *testing.T) {
+	for _, input := range []string{`1e1001`, `[1.5e-1001]`, `1e99999999999999999999`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseWithOptions(strings.NewReader(input), ParseOptions{ExactDecimals: true})
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	val, _ := ParseWithOptions(strings.NewReader(`{"a": 0.1, "b": 0.2, "c": 0.30}`), ParseOptions{ExactDecimals: true})
+	a, _ := val.Key("a").AsDecimal()
+	b, _ := val.Key("b").AsDecimal()
+	c, _ := val.Key("c").AsDecimal()
+
+	sum := a.Add(b)
+	if sum.Cmp(c) != 0 {
+		t.Errorf("expected %v got %v", c, sum)
+	}
+	if actual := sum.Sub(c).String(); actual != "0" {
+		t.Errorf("expected %v got %v", "0", actual)
+	}
+	if actual := a.Mul(b).String(); actual != "0.02" {
+		t.Errorf("expected %v got %v", "0.02", actual)
+	}
+	if actual := a.Float64(); actual != 0.1 {
+		t.Errorf("expected %v got %v", 0.1, actual)
+	}
+	if actual := (Decimal{}).Add(a).String(); actual != "0.1" {
+		t.Errorf("expected %v got %v", "0.1", actual)
+	}
+
+	r := a.Rat()
+	r.SetInt64(5)
+	if actual := a.String(); actual != "0.1" {
+		t.Errorf("expected %v got %v", "0.1", actual)
+	}
+
+	val.Set("sum", NewDecimal(sum))
+	val.Set("net", NewDecimal(a.Mul(a).Add(a.Mul(Decimal{}).Sub(a))))
+	actual, err := Marshal(val)
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if expected := `{"a":0.1,"b":0.2,"c":0.30,"sum":0.3,"net":-0.09}`; string(actual) != expected {
+		t.Errorf("expected %v got %v", expected, string(actual))
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{"12.50", "12.5", nil},
+		{"-1.5e3", "-1500", nil},
+		{"7", "7", nil},
+		{"abc", "0", ErrParse},
+		{`"1"`, "0", ErrParse},
+		{"1.", "0", ErrParse},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			d, err := ParseDecimal(test.input)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if actual := d.String(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if actual := NewDecimal(Decimal{}).JSON(); actual != "0.0" {
+		t.Errorf("expected %v got %v", "0.0", actual)
+	}
+}
@@ -0,0 +1,108 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Computes an RFC 6902 JSON Patch that transforms a into b. The patch is an
+// array of operation objects, which is empty if the values are Equal.
+//
+// Changed scalars and values whose types differ are replaced. Object members
+// are added, removed or diffed recursively, and the order of keys doesn't
+// matter. Arrays are diffed element by element, then extra elements are
+// removed from or added to the end. The values in the patch are shared with b
+// rather than copied.
+//
+// Returns ErrType if any of the values being compared has an unknown type.
+func Diff(a, b *Value) (*Value, error) {
+	patch := NewArray()
+	if err := diff(patch, a, b, nil); err != nil {
+		return &Value{}, err
+	}
+	return patch, nil
+}
+
+// Appends the operations that transform a into b to the patch, where both are
+// found at the given path.
+func diff(patch, a, b *Value, path []string) error {
+	for _, v := range []*Value{a, b} {
+		if v.Type() == typeUnknown {
+			return fmt.Errorf("%w: cannot diff value of type %v at %q", ErrType, v.jsonType, formatPointer(path))
+		}
+	}
+	if Equal(a, b) {
+		return nil
+	}
+	if a.jsonType != b.jsonType || (a.jsonType != Array && a.jsonType != Object) {
+		patch.Append(patchOperation("replace", path, b))
+		return nil
+	}
+
+	if a.jsonType == Array {
+		common := len(a.arrayValue)
+		if len(b.arrayValue) < common {
+			common = len(b.arrayValue)
+		}
+		for i := 0; i < common; i++ {
+			if err := diff(patch, a.arrayValue[i], b.arrayValue[i], appendPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		// Remove from the end so earlier indices stay valid
+		for i := len(a.arrayValue) - 1; i >= common; i-- {
+			patch.Append(patchOperation("remove", appendPath(path, strconv.Itoa(i)), nil))
+		}
+		for i := common; i < len(b.arrayValue); i++ {
+			patch.Append(patchOperation("add", appendPath(path, strconv.Itoa(i)), b.arrayValue[i]))
+		}
+		return nil
+	}
+
+	aMap, _ := a.AsObject()
+	bMap, _ := b.AsObject()
+	for _, k := range uniqueKeys(a) {
+		if bVal, ok := bMap[k]; ok {
+			if err := diff(patch, aMap[k], bVal, appendPath(path, k)); err != nil {
+				return err
+			}
+		} else {
+			patch.Append(patchOperation("remove", appendPath(path, k), nil))
+		}
+	}
+	for _, k := range uniqueKeys(b) {
+		if _, ok := aMap[k]; !ok {
+			patch.Append(patchOperation("add", appendPath(path, k), bMap[k]))
+		}
+	}
+	return nil
+}
+
+// Gets the keys of an object in order, without duplicates.
+func uniqueKeys(v *Value) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, p := range v.objectValue {
+		if !seen[p.key] {
+			seen[p.key] = true
+			keys = append(keys, p.key)
+		}
+	}
+	return keys
+}
+
+// Copies a path with one more reference token on the end.
+func appendPath(path []string, token string) []string {
+	return append(append([]string{}, path...), token)
+}
+
+// Creates an RFC 6902 operation object. The value is left out if nil.
+func patchOperation(op string, path []string, val *Value) *Value {
+	obj := NewObject()
+	obj.Set("op", NewString(op))
+	obj.Set("path", NewString(formatPointer(path)))
+	if val != nil {
+		obj.Set("value", val)
+	}
+	return obj
+}
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	for _, test := range []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{`null`, `null`, `[]`},
+		{`{"a": 1, "b": [1, 2]}`, `{"b": [1, 2], "a": 1}`, `[]`},
+		{`1`, `2`, `[{"op":"replace","path":"","value":2}]`},
+		{`1`, `1.0`, `[{"op":"replace","path":"","value":1.0}]`},
+		{`[]`, `{}`, `[{"op":"replace","path":"","value":{}}]`},
+		{`{"a": 1}`, `{"a": 2}`, `[{"op":"replace","path":"/a","value":2}]`},
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, `[{"op":"remove","path":"/b"}]`},
+		{`{"a": 1}`, `{"a": 1, "b/c": {"d": null}}`, `[{"op":"add","path":"/b~1c","value":{"d":null}}]`},
+		{`{"a": {"b": {"c": 1, "d": 2}}}`, `{"a": {"b": {"c": 1, "d": 3}}}`, `[{"op":"replace","path":"/a/b/d","value":3}]`},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`, `[]`},
+		{`[1, 2, 3]`, `[1, 5, 3]`, `[{"op":"replace","path":"/1","value":5}]`},
+		{`[1, 2, 3]`, `[1]`, `[{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+		{`[1]`, `[1, 2, 3]`, `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`},
+		{`[{"a": 1}]`, `[{"a": 1, "b": 2}]`, `[{"op":"add","path":"/0/b","value":2}]`},
+		{`{"x": 1, "y": 2, "z": 3}`, `{"w": 0, "y": 5}`, `[{"op":"remove","path":"/x"},{"op":"replace","path":"/y","value":5},{"op":"remove","path":"/z"},{"op":"add","path":"/w","value":0}]`},
+	} {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			a, _ := ParseString(test.a)
+			b, _ := ParseString(test.b)
+			patch, err := Diff(a, b)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			actual, _ := Marshal(patch)
+			if string(actual) != test.expected {
+				t.Errorf("expected %v got %v", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestDiffInvalid(t *testing.T) {
+	for _, test := range []struct {
+		a *Value
+		b *Value
+	}{
+		{&Value{jsonType: numTypes}, NewNull()},
+		{NewNull(), &Value{jsonType: numTypes}},
+		{NewArray(NewNull()), NewArray(&Value{jsonType: -1})},
+	} {
+		t.Run(test.a.String()+" "+test.b.String(), func(t *testing.T) {
+			if _, err := Diff(test.a, test.b); !errors.Is(err, ErrType) {
+				t.Errorf("expected %v got %v", ErrType, err)
+			}
+		})
+	}
+}
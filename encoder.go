@@ -0,0 +1,408 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// How much output to build up before sending it to a writer.
+const flushSize = 4096
+
+// Holds the output and settings used while serializing a value.
+type encodeState struct {
+	bytes.Buffer
+	prefix string
+	indent string
+	depth  int
+	// Whether to write canonical JSON as described in RFC 8785
+	canonical bool
+	// Whether to escape <, > and & in strings
+	escapeHTML bool
+	// Whether to write object members sorted by key
+	sortKeys bool
+	// Whether to write the comments kept from the source
+	comments bool
+	// Where to send output as it is produced, if anywhere
+	w       io.Writer
+	written int64
+}
+
+// Whether output is spread across multiple lines.
+func (e *encodeState) isIndented() bool {
+	return e.prefix != "" || e.indent != ""
+}
+
+// Starts a new line for the next member of an array or object.
+// Does nothing when no indentation is set.
+func (e *encodeState) newline() {
+	if !e.isIndented() {
+		return
+	}
+	e.WriteByte('\n')
+	e.WriteString(e.prefix)
+	for i := 0; i < e.depth; i++ {
+		e.WriteString(e.indent)
+	}
+}
+
+// Writes the comments that came before a value in the source, if there are
+// any and comments are being written. When indenting, each comment goes on
+// its own line. Otherwise block comments go right before the value, and line
+// comments are followed by a newline, since they only end at one.
+func (e *encodeState) writeComments(v *Value) {
+	if !e.comments {
+		return
+	}
+	for _, c := range v.comments {
+		e.WriteString(c)
+		if e.isIndented() {
+			e.newline()
+		} else if strings.HasPrefix(c, "//") {
+			e.WriteByte('\n')
+		}
+	}
+}
+
+// Writes the JSON representation of a value and all of its children.
+func (e *encodeState) marshal(v *Value) error {
+	switch v.jsonType {
+	case Null:
+		e.WriteString("null")
+	case Integer, Number:
+		if e.canonical {
+			return e.writeCanonicalNumber(v)
+		}
+		if v.rawNumber != "" {
+			e.WriteString(v.rawNumber)
+		} else {
+			return e.writeNumber(v)
+		}
+	case String:
+		e.writeString(v.stringValue)
+	case Boolean:
+		if v.booleanValue {
+			e.WriteString("true")
+		} else {
+			e.WriteString("false")
+		}
+	case Array:
+		e.WriteByte('[')
+		if len(v.arrayValue) == 0 {
+			e.WriteByte(']')
+			break
+		}
+		e.depth++
+		for i, val := range v.arrayValue {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.newline()
+			e.writeComments(val)
+			if err := e.marshal(val); err != nil {
+				return err
+			}
+			if err := e.flush(false); err != nil {
+				return err
+			}
+		}
+		e.depth--
+		e.newline()
+		e.WriteByte(']')
+	case Object:
+		e.WriteByte('{')
+		pairs := v.objectValue
+		if e.canonical {
+			pairs = canonicalPairs(pairs)
+		} else if e.sortKeys {
+			pairs = sortedPairs(pairs)
+		}
+		if len(pairs) == 0 {
+			e.WriteByte('}')
+			break
+		}
+		e.depth++
+		for i, pair := range pairs {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.newline()
+			// Comments before a member's value were before its key
+			e.writeComments(pair.val)
+			e.writeString(pair.key)
+			e.WriteByte(':')
+			if e.isIndented() {
+				e.WriteByte(' ')
+			}
+			if err := e.marshal(pair.val); err != nil {
+				return err
+			}
+			if err := e.flush(false); err != nil {
+				return err
+			}
+		}
+		e.depth--
+		e.newline()
+		e.WriteByte('}')
+	default:
+		return fmt.Errorf("%w: cannot marshal value of type %v", ErrType, v.jsonType)
+	}
+	return nil
+}
+
+// Makes a copy of an object's members sorted by key, compared byte by byte.
+// Members with the same key stay in the order they were in.
+func sortedPairs(pairs []pair) []pair {
+	sorted := append([]pair{}, pairs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+	return sorted
+}
+
+// Sends the output built up so far to the writer, if there is one. Unless
+// forced, it waits until there is enough output to be worth writing, so that
+// large values are streamed out without being held in memory all at once.
+func (e *encodeState) flush(force bool) error {
+	if e.w == nil || (!force && e.Len() < flushSize) {
+		return nil
+	}
+	n, err := e.w.Write(e.Bytes())
+	e.written += int64(n)
+	e.Reset()
+	return err
+}
+
+// Writes an integer or floating point number. Floating point numbers use the
+// shortest digits that parse back to the same value, in exponent form if they
+// are very large or very small, the same as ECMAScript and encoding/json.
+// Whole numbers in fixed form get a trailing ".0" so that they parse back as
+// a Number rather than an Integer. Returns ErrRange for NaN and infinities,
+// which JSON can't represent.
+func (e *encodeState) writeNumber(v *Value) error {
+	if v.jsonType == Integer {
+		if v.bigValue != nil {
+			e.WriteString(v.bigValue.String())
+		} else {
+			e.WriteString(strconv.FormatInt(v.integerValue, 10))
+		}
+		return nil
+	}
+	if math.IsNaN(v.numberValue) || math.IsInf(v.numberValue, 0) {
+		return fmt.Errorf("%w: cannot marshal number %v", ErrRange, v.numberValue)
+	}
+	str := formatFloat(v.numberValue)
+	e.WriteString(str)
+	if !strings.ContainsAny(str, ".eE") {
+		e.WriteString(".0")
+	}
+	return nil
+}
+
+// Whether a byte is one of the characters that are escaped to make output
+// safe to embed in HTML.
+func isHTMLChar(b byte) bool {
+	return b == '<' || b == '>' || b == '&'
+}
+
+// Formats a finite float in fixed notation when 1e-6 <= |f| < 1e21, and in
+// exponent notation such as 1e+21 or 1.5e-7 otherwise.
+func formatFloat(f float64) string {
+	if abs := math.Abs(f); abs == 0 || (abs >= 1e-6 && abs < 1e21) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	str := strconv.FormatFloat(f, 'e', -1, 64)
+	// strconv pads the exponent to two digits, as in 1e-07
+	if n := len(str); str[n-4] == 'e' && str[n-2] == '0' {
+		str = str[:n-2] + str[n-1:]
+	}
+	return str
+}
+
+// Writes a quoted string, escaping it according to RFC 8259, and escaping
+// <, > and & as well if escapeHTML is set. Invalid UTF-8 is replaced with U+FFFD.
+func (e *encodeState) writeString(s string) {
+	e.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && !(e.escapeHTML && isHTMLChar(b)) {
+				i++
+				continue
+			}
+			e.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				e.WriteByte('\\')
+				e.WriteByte(b)
+			case '\b':
+				e.WriteString(`\b`)
+			case '\f':
+				e.WriteString(`\f`)
+			case '\n':
+				e.WriteString(`\n`)
+			case '\r':
+				e.WriteString(`\r`)
+			case '\t':
+				e.WriteString(`\t`)
+			default:
+				e.WriteString(`\u00`)
+				e.WriteByte(hexDigits[b>>4])
+				e.WriteByte(hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			e.WriteString(s[start:i])
+			if e.canonical {
+				e.WriteRune(utf8.RuneError)
+			} else {
+				e.WriteString(`\ufffd`)
+			}
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	e.WriteString(s[start:])
+	e.WriteByte('"')
+}
+
+// Serializes a value as compact, valid JSON. Unlike String, the output has no
+// extra whitespace and strings are escaped according to RFC 8259. Like
+// encoding/json, it also escapes <, > and & so that the output is safe to
+// embed in HTML; an Encoder with SetEscapeHTML(false) leaves them as is.
+// Returns ErrType if the value or any of its children has an unknown type,
+// and ErrRange if it contains a NaN or infinite number.
+func Marshal(v *Value) ([]byte, error) {
+	e := &encodeState{escapeHTML: true}
+	if err := e.marshal(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// Serializes a value as valid JSON like Marshal, but indented across
+// multiple lines like encoding/json's MarshalIndent. Each line after the
+// first starts with prefix followed by one copy of indent per level of
+// nesting, and there is no trailing newline. Empty arrays and objects are
+// still written as [] and {}. Returns the same errors as Marshal.
+func MarshalIndent(v *Value, prefix, indent string) ([]byte, error) {
+	e := &encodeState{prefix: prefix, indent: indent, escapeHTML: true}
+	if err := e.marshal(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// Implements the encoding/json Marshaler interface. See Marshal.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return Marshal(v)
+}
+
+// Gets the value as compact, valid JSON, the same as Marshal, for use where
+// a string is needed. Unlike String, which is only meant for debugging, the
+// result can always be parsed back into an equal value. Returns an empty
+// string, which is never valid JSON, if Marshal would fail.
+func (v *Value) JSON() string {
+	b, err := Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Implements io.WriterTo, writing the same compact JSON as Marshal to w as it
+// is produced rather than building it all in memory first. Returns the number
+// of bytes written, and ErrType or ErrRange like Marshal, or any error produced
+// by the writer. If an error occurs, part of the value may already be written.
+func (v *Value) WriteTo(w io.Writer) (int64, error) {
+	e := &encodeState{w: w, escapeHTML: true}
+	if err := e.marshal(v); err != nil {
+		return e.written, err
+	}
+	err := e.flush(true)
+	return e.written, err
+}
+
+// Writes JSON values to an output stream.
+type Encoder struct {
+	w          io.Writer
+	prefix     string
+	indent     string
+	escapeHTML bool
+	sortKeys   bool
+	comments   bool
+}
+
+// Creates a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, escapeHTML: true}
+}
+
+// Sets the encoder to format each subsequent value as if indented
+// across multiple lines. Each line after the first starts with prefix
+// followed by one copy of indent per level of nesting. Empty arrays and
+// objects are still written as [] and {}. Calling SetIndent("", "")
+// turns indentation off.
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.prefix = prefix
+	enc.indent = indent
+}
+
+// Sets whether <, > and & in strings are written as \u003c, \u003e and \u0026,
+// so that the output can be safely embedded in HTML, such as in a <script>
+// tag. This is on by default, the same as in Marshal and encoding/json.
+// Turning it off makes the output shorter when it won't be put in HTML.
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	enc.escapeHTML = on
+}
+
+// Sets whether the members of each object are written sorted by key,
+// compared byte by byte, rather than in the order they were parsed or added,
+// so that generated files stay the same from run to run and diff cleanly.
+// Members with the same key keep their order. The value itself isn't changed.
+// This is off by default.
+func (enc *Encoder) SetSortKeys(on bool) {
+	enc.sortKeys = on
+}
+
+// Sets whether the comments kept by parsing with PreserveComments are
+// written back out before the values they came before, so that a JSONC
+// document such as a config file can be edited and saved without losing
+// them. Comments before an object member are written before its key. When
+// indenting, each comment goes on its own line; otherwise line comments are
+// followed by a newline, since nothing else ends them. The output is only
+// valid JSON if there are no comments, and comments that the parser dropped,
+// such as those before a closing bracket, can't be written back. This is off
+// by default, and Marshal never writes comments.
+func (enc *Encoder) SetComments(on bool) {
+	enc.comments = on
+}
+
+// Writes the JSON encoding of v to the stream, followed by a newline.
+// Returns ErrType if the value or any of its children has an unknown type,
+// ErrRange if it contains a NaN or infinite number, or any error produced
+// by the underlying writer.
+func (enc *Encoder) Encode(v *Value) error {
+	e := &encodeState{prefix: enc.prefix, indent: enc.indent, escapeHTML: enc.escapeHTML, sortKeys: enc.sortKeys, comments: enc.comments}
+	e.writeComments(v)
+	if err := e.marshal(v); err != nil {
+		return err
+	}
+	e.WriteByte('\n')
+	_, err := enc.w.Write(e.Bytes())
+	return err
+}
@@ -0,0 +1,423 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+type mockFileErrorOnWrite struct{}
+
+func (f mockFileErrorOnWrite) Write(p []byte) (n int, err error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestMarshal(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected string
+	}{
+		{&Value{}, `null`},
+		{&Value{jsonType: Integer, integerValue: -5}, `-5`},
+		{&Value{jsonType: Integer, bigValue: bigIntFromString("-123456789012345678901234567890")}, `-123456789012345678901234567890`},
+		{&Value{jsonType: Number, numberValue: -5.12}, `-5.12`},
+		{&Value{jsonType: Number, numberValue: -5}, `-5.0`},
+		{&Value{jsonType: Number, numberValue: 1e20}, `100000000000000000000.0`},
+		{&Value{jsonType: Number, numberValue: 1e21}, `1e+21`},
+		{&Value{jsonType: Number, numberValue: -1.5e300}, `-1.5e+300`},
+		{&Value{jsonType: Number, numberValue: 0.000001}, `0.000001`},
+		{&Value{jsonType: Number, numberValue: 1.5e-7}, `1.5e-7`},
+		{&Value{jsonType: Number, numberValue: 1e-10}, `1e-10`},
+		{&Value{jsonType: Number, numberValue: 5e-324}, `5e-324`},
+		{&Value{jsonType: Number, numberValue: 0.30000000000000004}, `0.30000000000000004`},
+		{&Value{jsonType: Number, numberValue: math.Copysign(0, -1)}, `-0.0`},
+		{&Value{jsonType: Boolean, booleanValue: true}, `true`},
+		{&Value{jsonType: Boolean, booleanValue: false}, `false`},
+		{&Value{jsonType: String, stringValue: "-5.12"}, `"-5.12"`},
+		{&Value{jsonType: String, stringValue: "\"\\/\b\f\n\r\t"}, `"\"\\/\b\f\n\r\t"`},
+		{&Value{jsonType: String, stringValue: "\x00\x1f\x7f"}, `"\u0000\u001f` + "\x7f" + `"`},
+		{&Value{jsonType: String, stringValue: "Hello, 世界"}, `"Hello, 世界"`},
+		{&Value{jsonType: String, stringValue: "\xff"}, `"\ufffd"`},
+		{&Value{jsonType: String, stringValue: "<script>a && b</script>"}, `"\u003cscript\u003ea \u0026\u0026 b\u003c/script\u003e"`},
+		{&Value{jsonType: Array, arrayValue: []*Value{}}, `[]`},
+		{&Value{jsonType: Array, arrayValue: []*Value{
+			{},
+			{jsonType: Integer, integerValue: -5},
+			{jsonType: String, stringValue: "-5.12"},
+			{jsonType: Boolean, booleanValue: true},
+		}}, `[null,-5,"-5.12",true]`},
+		{&Value{jsonType: Object, objectValue: []pair{}}, `{}`},
+		{&Value{jsonType: Object, objectValue: []pair{
+			{"a", &Value{}},
+			{"b\n", &Value{jsonType: Integer, integerValue: -5}},
+			{"c", &Value{jsonType: Array, arrayValue: []*Value{{}}}},
+		}}, `{"a":null,"b\n":-5,"c":[null]}`},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			actual, err := Marshal(test.input)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %v got %v", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	s := `{
+		"null": null,
+		"true": true,
+		"false": false,
+		"number": -105.754e+7,
+		"string": "-10\"\n\r\f\b\t\/\\Ư\u0000",
+		"array": [null, true, -10.55e-15, "-10\"\n\r\f\b\t\/\\Ư", "\\/"],
+		"object": {}
+	}`
+	expected, _ := ParseString(s)
+	b, err := Marshal(expected)
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	actual, err := ParseBytes(b)
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if !equals(expected, actual) {
+		t.Errorf("expected %v\ngot %v", expected, actual)
+	}
+}
+
+func TestMarshalRawNumbers(t *testing.T) {
+	s := `[1e3,1.50,-0.0,0E+01,123456789012345678901234567890]`
+	val, _ := ParseString(s)
+	actual, err := Marshal(val)
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if string(actual) != s {
+		t.Errorf("expected %v got %v", s, string(actual))
+	}
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	for _, test := range []*Value{
+		{jsonType: numTypes},
+		{jsonType: Array, arrayValue: []*Value{{jsonType: -1}}},
+		{jsonType: Object, objectValue: []pair{{"a", &Value{jsonType: 1000}}}},
+	} {
+		t.Run(test.String(), func(t *testing.T) {
+			if _, err := Marshal(test); err == nil {
+				t.Errorf("expected error got none")
+			}
+		})
+	}
+}
+
+func TestMarshalNonFinite(t *testing.T) {
+	for _, test := range []*Value{
+		NewNumber(math.NaN()),
+		NewNumber(math.Inf(1)),
+		NewArray(NewInt(1), NewNumber(math.Inf(-1))),
+	} {
+		t.Run(test.String(), func(t *testing.T) {
+			if _, err := Marshal(test); !errors.Is(err, ErrRange) {
+				t.Errorf("expected %v got %v", ErrRange, err)
+			}
+			if err := NewEncoder(&bytes.Buffer{}).Encode(test); !errors.Is(err, ErrRange) {
+				t.Errorf("expected %v got %v", ErrRange, err)
+			}
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		prefix   string
+		indent   string
+		expected string
+	}{
+		{`{"a": [1, {}], "b": [], "c": "<x>"}`, "", "  ", "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": [],\n  \"c\": \"\\u003cx\\u003e\"\n}"},
+		{`[true, null]`, "\t", "  ", "[\n\t  true,\n\t  null\n\t]"},
+		{`{}`, "", "  ", `{}`},
+		{`[]`, "", "  ", `[]`},
+		{`1.5`, "", "  ", `1.5`},
+		{`{"a": 1}`, "", "", `{"a":1}`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			b, err := MarshalIndent(MustParse(test.input), test.prefix, test.indent)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if string(b) != test.expected {
+				t.Errorf("expected %v got %s", test.expected, b)
+			}
+			if expected := MustParse(test.input); !equals(expected, MustParse(string(b))) {
+				t.Errorf("expected %v got %s", expected, b)
+			}
+		})
+	}
+
+	if _, err := MarshalIndent(NewNumber(math.NaN()), "", "  "); !errors.Is(err, ErrRange) {
+		t.Errorf("expected %v got %v", ErrRange, err)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	val := &Value{jsonType: Array, arrayValue: []*Value{{}}}
+	actual, err := val.MarshalJSON()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if string(actual) != `[null]` {
+		t.Errorf("expected %v got %v", `[null]`, string(actual))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected string
+	}{
+		{MustParse(`{"a": [1, 2.5, "x\ny"], "b": {}}`), `{"a":[1,2.5,"x\ny"],"b":{}}`},
+		{NewNumber(5), `5.0`},
+		{NewString("\"quoted\""), `"\"quoted\""`},
+		{NewNull(), `null`},
+		{NewNumber(math.NaN()), ``},
+		{&Value{jsonType: numTypes}, ``},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			actual := test.input.JSON()
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if actual == "" {
+				return
+			}
+			if parsed, err := ParseString(actual); err != nil || !Equal(test.input, parsed) {
+				t.Errorf("expected %v got %v", test.input, parsed)
+			}
+		})
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	val, _ := ParseString(`{"a": [1, {}, []], "b": {"c": null}, "d": "e"}`)
+	for _, test := range []struct {
+		prefix   string
+		indent   string
+		expected string
+	}{
+		{"", "", `{"a":[1,{},[]],"b":{"c":null},"d":"e"}` + "\n"},
+		{"", "\t", "{\n\t\"a\": [\n\t\t1,\n\t\t{},\n\t\t[]\n\t],\n\t\"b\": {\n\t\t\"c\": null\n\t},\n\t\"d\": \"e\"\n}\n"},
+		{"//", "  ", "{\n//  \"a\": [\n//    1,\n//    {},\n//    []\n//  ],\n//  \"b\": {\n//    \"c\": null\n//  },\n//  \"d\": \"e\"\n//}\n"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			b := &strings.Builder{}
+			enc := NewEncoder(b)
+			enc.SetIndent(test.prefix, test.indent)
+			if err := enc.Encode(val); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, b.String())
+			}
+		})
+	}
+}
+
+func TestEncoderEscapeHTML(t *testing.T) {
+	val := MustParse(`{"<a>": "x & y"}`)
+	for _, test := range []struct {
+		escape   bool
+		expected string
+	}{
+		{true, `{"\u003ca\u003e":"x \u0026 y"}` + "\n"},
+		{false, `{"<a>":"x & y"}` + "\n"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			b := &strings.Builder{}
+			enc := NewEncoder(b)
+			enc.SetEscapeHTML(test.escape)
+			if err := enc.Encode(val); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, b.String())
+			}
+			if actual, _ := ParseString(b.String()); !Equal(val, actual) {
+				t.Errorf("expected %v got %v", val, actual)
+			}
+		})
+	}
+
+	b := &strings.Builder{}
+	NewEncoder(b).Encode(val)
+	if expected := `{"\u003ca\u003e":"x \u0026 y"}` + "\n"; b.String() != expected {
+		t.Errorf("expected %v got %v", expected, b.String())
+	}
+	if canonical, _ := MarshalCanonical(val); string(canonical) != `{"<a>":"x & y"}` {
+		t.Errorf("expected %v got %v", `{"<a>":"x & y"}`, string(canonical))
+	}
+	if val.String() != `{"<a>": "x & y"}` {
+		t.Errorf("expected %v got %v", `{"<a>": "x & y"}`, val)
+	}
+}
+
+func TestEncoderSortKeys(t *testing.T) {
+	input := `{"b": 1, "a": {"z": [{"y": 1, "x": 2}], "é": 3, "Z": 4}, "b": 2, "": 5}`
+	val := MustParse(input)
+	for _, test := range []struct {
+		sort     bool
+		indent   string
+		expected string
+	}{
+		{false, "", `{"b":1,"a":{"z":[{"y":1,"x":2}],"é":3,"Z":4},"b":2,"":5}` + "\n"},
+		{true, "", `{"":5,"a":{"Z":4,"z":[{"x":2,"y":1}],"é":3},"b":1,"b":2}` + "\n"},
+		{true, "  ", "{\n  \"\": 5,\n  \"a\": {\n    \"Z\": 4,\n    \"z\": [\n      {\n        \"x\": 2,\n        \"y\": 1\n      }\n    ],\n    \"é\": 3\n  },\n  \"b\": 1,\n  \"b\": 2\n}\n"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			b := &strings.Builder{}
+			enc := NewEncoder(b)
+			enc.SetSortKeys(test.sort)
+			enc.SetIndent("", test.indent)
+			if err := enc.Encode(val); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, b.String())
+			}
+		})
+	}
+
+	// The value itself keeps its order
+	if expected := MustParse(input); val.String() != expected.String() {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+}
+
+func TestEncoderComments(t *testing.T) {
+	input := `// settings
+{
+	// the name
+	"name": /* inline */ "app",
+	"ports": [
+		80, // http
+		/* https */ 443
+	]
+}`
+	options := DefaultParseOptions()
+	options.PreserveComments = true
+	val, err := ParseWithOptions(strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, test := range []struct {
+		comments bool
+		indent   string
+		expected string
+	}{
+		{false, "", `{"name":"app","ports":[80,443]}` + "\n"},
+		{true, "", "// settings\n{// the name\n/* inline */\"name\":\"app\",\"ports\":[80,// http\n/* https */443]}\n"},
+		{true, "  ", "// settings\n{\n  // the name\n  /* inline */\n  \"name\": \"app\",\n  \"ports\": [\n    80,\n    // http\n    /* https */\n    443\n  ]\n}\n"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			b := &strings.Builder{}
+			enc := NewEncoder(b)
+			enc.SetComments(test.comments)
+			enc.SetIndent("", test.indent)
+			if err := enc.Encode(val); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if b.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, b.String())
+			}
+
+			// The output parses back to the same value and comments
+			reparsed, err := ParseWithOptions(strings.NewReader(b.String()), options)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if !equals(val, reparsed) {
+				t.Errorf("expected %v got %v", val, reparsed)
+			}
+			if expected, actual := strings.Join(val.Get("ports", 1).Comments(), "|"), strings.Join(reparsed.Get("ports", 1).Comments(), "|"); test.comments && expected != actual {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+		})
+	}
+
+	if b, _ := Marshal(val); string(b) != `{"name":"app","ports":[80,443]}` {
+		t.Errorf("expected %v got %s", `{"name":"app","ports":[80,443]}`, b)
+	}
+}
+
+func TestEncoderInvalid(t *testing.T) {
+	b := &strings.Builder{}
+	if err := NewEncoder(b).Encode(&Value{jsonType: numTypes}); err == nil {
+		t.Errorf("expected error got none")
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no output got %v", b.String())
+	}
+
+	if err := NewEncoder(mockFileErrorOnWrite{}).Encode(&Value{}); err == nil {
+		t.Errorf("expected error got none")
+	}
+}
+
+type mockCountingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *mockCountingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestWriteTo(t *testing.T) {
+	large := NewArray()
+	for i := 0; i < 10000; i++ {
+		large.Append(NewObject())
+		large.Index(i).Set("i", NewInt(int64(i)))
+	}
+
+	for _, val := range []*Value{
+		MustParse(`{"a": [1, 2.5, "b\n"], "c": {}}`),
+		large,
+	} {
+		t.Run(val.Type().String(), func(t *testing.T) {
+			expected, _ := Marshal(val)
+			w := &mockCountingWriter{}
+			n, err := val.WriteTo(w)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if n != int64(len(expected)) {
+				t.Errorf("expected %v got %v", len(expected), n)
+			}
+			if w.String() != string(expected) {
+				t.Errorf("expected %v got %v", string(expected), w.String())
+			}
+		})
+	}
+
+	w := &mockCountingWriter{}
+	large.WriteTo(w)
+	if w.writes < 2 {
+		t.Errorf("expected multiple writes got %v", w.writes)
+	}
+
+	if _, err := large.WriteTo(mockFileErrorOnWrite{}); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected %v got %v", io.ErrClosedPipe, err)
+	}
+	if _, err := NewArray(NewNumber(math.NaN())).WriteTo(&bytes.Buffer{}); !errors.Is(err, ErrRange) {
+		t.Errorf("expected %v got %v", ErrRange, err)
+	}
+}
@@ -0,0 +1,165 @@
+package json
+
+import (
+	"math"
+	"math/big"
+)
+
+// Reports whether two values are structurally equal.
+//
+// Values must have the same type to be equal. Integer and Number are
+// treated as distinct types, so the Integer 5 does not equal the Number 5.0.
+// Arrays are equal if their elements are equal in order. Objects are compared
+// as unordered sets of key/value pairs. If an object contains duplicate keys,
+// only the last value for that key is considered, the same as in AsObject.
+func Equal(a, b *Value) bool {
+	return equal(a, b, comparison{})
+}
+
+// Reports whether two values are structurally equal like Equal, except that
+// Integer and Number values are compared by their numeric value, so the
+// Integer 5 equals the Number 5.0. Numbers are still never equal to strings,
+// booleans or any other type.
+//
+// The comparison is exact rather than done in float64, so there is no
+// precision loss near the limits of int64 or for big integers: the Integer
+// 9007199254740993 does not equal the Number 9007199254740992.0, even though
+// they are the same when converted to float64. NaN is never equal to anything.
+func EqualLoose(a, b *Value) bool {
+	return equal(a, b, comparison{loose: true})
+}
+
+// Reports whether two values are structurally equal like EqualLoose, except
+// that numbers are equal if they differ by no more than epsilon, so that
+// documents holding computed numbers can be compared despite rounding
+// errors. Integer and Number values are compared as float64s. Infinities
+// are only equal to themselves, and NaN is never equal to anything.
+// Everything other than numbers is compared exactly.
+func EqualApprox(a, b *Value, epsilon float64) bool {
+	return equal(a, b, comparison{loose: true, approx: true, epsilon: epsilon})
+}
+
+// How equal compares numbers.
+type comparison struct {
+	loose   bool // treat Integer and Number as one type
+	approx  bool // compare numbers within epsilon
+	epsilon float64
+}
+
+// Compares two values, treating numbers according to c.
+func equal(a, b *Value, c comparison) bool {
+	if c.approx && isNumeric(a) && isNumeric(b) {
+		x, _ := a.AsNumber()
+		y, _ := b.AsNumber()
+		if math.IsInf(x, 0) || math.IsInf(y, 0) {
+			return x == y
+		}
+		return math.Abs(x-y) <= c.epsilon
+	}
+	if c.loose && isNumeric(a) && isNumeric(b) && a.jsonType != b.jsonType {
+		return numbersEqual(a, b)
+	}
+	if a.jsonType != b.jsonType {
+		return false
+	}
+	switch a.jsonType {
+	case Null:
+		return true
+	case Boolean:
+		return a.booleanValue == b.booleanValue
+	case Integer:
+		if a.bigValue != nil || b.bigValue != nil {
+			return a.bigInt().Cmp(b.bigInt()) == 0
+		}
+		return a.integerValue == b.integerValue
+	case Number:
+		return a.numberValue == b.numberValue
+	case String:
+		return a.stringValue == b.stringValue
+	case Array:
+		if len(a.arrayValue) != len(b.arrayValue) {
+			return false
+		}
+		for i := range a.arrayValue {
+			if !equal(a.arrayValue[i], b.arrayValue[i], c) {
+				return false
+			}
+		}
+		return true
+	case Object:
+		aMap, _ := a.AsObject()
+		bMap, _ := b.AsObject()
+		if len(aMap) != len(bMap) {
+			return false
+		}
+		for k, aVal := range aMap {
+			bVal, ok := bMap[k]
+			if !ok || !equal(aVal, bVal, c) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Whether a value is an Integer or a Number.
+func isNumeric(v *Value) bool {
+	return v.jsonType == Integer || v.jsonType == Number
+}
+
+// Reports whether an Integer and a Number have exactly the same value.
+func numbersEqual(a, b *Value) bool {
+	if a.jsonType == Number {
+		a, b = b, a
+	}
+	if math.IsNaN(b.numberValue) {
+		return false
+	}
+	// A big.Float holds any int64, big.Int or float64 exactly
+	i := new(big.Float).SetInt(a.bigInt())
+	return i.Cmp(big.NewFloat(b.numberValue)) == 0
+}
+
+// Makes a new array from the elements of an array with duplicates removed,
+// leaving the original unchanged. Elements are compared with Equal, so
+// objects and arrays are compared by their contents, and the first
+// occurrence of each element is kept in its original position.
+//
+// Returns ErrType if the value is not an array.
+func (v *Value) Unique() (*Value, error) {
+	if v.jsonType != Array {
+		return &Value{}, v.typeError(Array)
+	}
+	result := NewArray()
+	for _, val := range v.arrayValue {
+		if !result.Contains(val) {
+			result.arrayValue = append(result.arrayValue, val.Clone())
+		}
+	}
+	return result, nil
+}
+
+// Reports whether an array has an element, or an object has a member value,
+// that is equal to the needle according to Equal, so that arrays and objects
+// are compared by their contents. If an object has duplicate keys, only the
+// last value for each key is considered, the same as in Equal. Always false
+// for anything other than an array or object.
+func (v *Value) Contains(needle *Value) bool {
+	switch v.jsonType {
+	case Array:
+		for _, elem := range v.arrayValue {
+			if Equal(elem, needle) {
+				return true
+			}
+		}
+	case Object:
+		members, _ := v.AsObject()
+		for _, val := range members {
+			if Equal(val, needle) {
+				return true
+			}
+		}
+	}
+	return false
+}
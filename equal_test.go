@@ -0,0 +1,198 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	for _, test := range []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{`null`, `null`, true},
+		{`true`, `true`, true},
+		{`true`, `false`, false},
+		{`true`, `null`, false},
+		{`5`, `5`, true},
+		{`5`, `6`, false},
+		{`5`, `5.0`, false},
+		{`123456789012345678901234567890`, `123456789012345678901234567890`, true},
+		{`123456789012345678901234567890`, `123456789012345678901234567891`, false},
+		{`123456789012345678901234567890`, `5`, false},
+		{`5.5`, `5.5`, true},
+		{`5.5`, `5.25`, false},
+		{`"a"`, `"a"`, true},
+		{`"a"`, `"b"`, false},
+		{`[]`, `[]`, true},
+		{`[1, 2]`, `[1, 2]`, true},
+		{`[1, 2]`, `[2, 1]`, false},
+		{`[1, 2]`, `[1, 2, 3]`, false},
+		{`{}`, `{}`, true},
+		{`{"a": 1, "b": [true]}`, `{"b": [true], "a": 1}`, true},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "c": 2}`, false},
+		{`{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`, false},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`, true},
+		{`{"a": 1, "a": 2}`, `{"a": 1}`, false},
+		{`{}`, `[]`, false},
+	} {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			a, _ := ParseString(test.a)
+			b, _ := ParseString(test.b)
+			if actual := Equal(a, b); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if actual := Equal(b, a); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if Equal(&Value{jsonType: numTypes}, &Value{jsonType: numTypes}) {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
+
+func TestEqualLoose(t *testing.T) {
+	for _, test := range []struct {
+		a        *Value
+		b        *Value
+		expected bool
+	}{
+		{NewInt(5), NewNumber(5), true},
+		{NewInt(5), NewNumber(5.5), false},
+		{NewInt(-0), NewNumber(math.Copysign(0, -1)), true},
+		{NewInt(5), NewInt(5), true},
+		{NewNumber(5), NewNumber(5), true},
+		{NewInt(5), NewString("5"), false},
+		{NewNumber(1), NewBool(true), false},
+		{NewInt(9007199254740993), NewNumber(9007199254740992), false},
+		{NewInt(9007199254740992), NewNumber(9007199254740992), true},
+		{NewInt(math.MaxInt64), NewNumber(math.MaxInt64), false},
+		{MustParse("9223372036854775808"), NewNumber(math.MaxInt64), true},
+		{MustParse("100000000000000000000000"), NewNumber(1e23), false},
+		{NewInt(0), NewNumber(math.NaN()), false},
+		{NewInt(0), NewNumber(math.Inf(1)), false},
+		{MustParse(`[1, {"a": 2.0}]`), MustParse(`[1.0, {"a": 2}]`), true},
+		{MustParse(`[1, {"a": 2.5}]`), MustParse(`[1.0, {"a": 2}]`), false},
+	} {
+		t.Run(test.a.String()+" "+test.b.String(), func(t *testing.T) {
+			if actual := EqualLoose(test.a, test.b); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if actual := EqualLoose(test.b, test.a); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if Equal(NewInt(5), NewNumber(5)) {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
+
+func TestEqualApprox(t *testing.T) {
+	for _, test := range []struct {
+		a        *Value
+		b        *Value
+		epsilon  float64
+		expected bool
+	}{
+		{NewNumber(0.30000000000000004), NewNumber(0.3), 0, false},
+		{NewNumber(0.30000000000000004), NewNumber(0.3), 1e-9, true},
+		{NewNumber(1), NewNumber(1.1), 0.01, false},
+		{NewNumber(1), NewNumber(1.1), 0.2, true},
+		{NewInt(5), NewNumber(5.0000001), 1e-6, true},
+		{NewInt(5), NewInt(6), 0.5, false},
+		{NewInt(5), NewInt(6), 1, true},
+		{NewNumber(math.Inf(1)), NewNumber(math.Inf(1)), 0, true},
+		{NewNumber(math.Inf(1)), NewNumber(math.Inf(-1)), math.Inf(1), false},
+		{NewNumber(math.NaN()), NewNumber(math.NaN()), 1, false},
+		{NewNumber(5), NewString("5"), 1, false},
+		{NewString("a"), NewString("a"), 0, true},
+		{MustParse(`{"a": [1.0000001, "x"], "b": null}`), MustParse(`{"b": null, "a": [1, "x"]}`), 1e-6, true},
+		{MustParse(`{"a": [1.1, "x"]}`), MustParse(`{"a": [1, "x"]}`), 1e-6, false},
+		{MustParse(`{"a": [1, "x"]}`), MustParse(`{"a": [1, "y"]}`), 1, false},
+	} {
+		t.Run(test.a.String()+" "+test.b.String(), func(t *testing.T) {
+			if actual := EqualApprox(test.a, test.b, test.epsilon); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if actual := EqualApprox(test.b, test.a, test.epsilon); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{`[]`, `[]`},
+		{`["a", "b", "a", "c", "b"]`, `["a", "b", "c"]`},
+		{`[1, 1.0, "1", 1, true, null, null]`, `[1, 1.0, "1", true, null]`},
+		{`[{"a": 1, "b": 2}, {"b": 2, "a": 1}, {"a": 1}]`, `[{"a": 1, "b": 2}, {"a": 1}]`},
+		{`[[1, 2], [2, 1], [1, 2]]`, `[[1, 2], [2, 1]]`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			input := MustParse(test.input)
+			original := input.Clone()
+			actual, err := input.Unique()
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.expected); !Equal(expected, actual) {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if !Equal(original, input) {
+				t.Errorf("expected %v got %v", original, input)
+			}
+		})
+	}
+
+	val := MustParse(`[{"a": 1}]`)
+	unique, _ := val.Unique()
+	unique.Index(0).Set("a", NewInt(2))
+	if actual := val.Index(0).Key("a"); !Equal(NewInt(1), actual) {
+		t.Errorf("expected %v got %v", 1, actual)
+	}
+
+	if _, err := MustParse(`{}`).Unique(); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestContains(t *testing.T) {
+	for _, test := range []struct {
+		haystack string
+		needle   string
+		expected bool
+	}{
+		{`[1, "a", null]`, `"a"`, true},
+		{`[1, "a", null]`, `null`, true},
+		{`[1, "a", null]`, `2`, false},
+		{`[1]`, `1.0`, false},
+		{`[]`, `1`, false},
+		{`[{"id": 1, "tags": ["x"]}, {"id": 2}]`, `{"tags": ["x"], "id": 1}`, true},
+		{`[{"id": 1, "tags": ["x"]}]`, `{"id": 1}`, false},
+		{`[[1, 2]]`, `[2, 1]`, false},
+		{`{"a": {"b": 1}, "c": 2}`, `{"b": 1}`, true},
+		{`{"a": {"b": 1}, "c": 2}`, `2`, true},
+		{`{"a": {"b": 1}, "c": 2}`, `"a"`, false},
+		{`{"a": 1, "a": 2}`, `2`, true},
+		{`{"a": 1, "a": 2}`, `1`, false},
+		{`"abc"`, `"abc"`, false},
+		{`5`, `5`, false},
+	} {
+		t.Run(test.haystack+" "+test.needle, func(t *testing.T) {
+			if actual := MustParse(test.haystack).Contains(MustParse(test.needle)); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -43,6 +43,10 @@ func TestUsage(t *testing.T) {
 	if i != n {
 		t.Error("It works this time, but this isn't the best way to check for floating point equivalency, btw")
 	}
+	// EqualApprox is the better way, and works on whole documents, too.
+	if !json.EqualApprox(m["integer"], m["number"], 1e-9) {
+		t.Error("JSON numbers aren't close enough!")
+	}
 
 	// Arrays are represented as slices of JSON values.
 	a, _ := m["array"].AsArray()
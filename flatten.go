@@ -0,0 +1,72 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Makes a single-level object from an array or object, with a member for
+// each leaf keyed by the keys along its path joined with sep, so that
+// {"a": {"b": 1}, "c": [true]} with a sep of "." becomes {"a.b": 1, "c.0": true}.
+// Array elements are keyed by their index. Leaves are scalars and empty
+// arrays and objects, the same as in Paths, and are copied so that the
+// original is left unchanged. Keys that contain sep are not escaped, so such
+// keys can't be told apart from nested ones.
+//
+// Returns ErrType if the value is neither an array nor an object.
+func (v *Value) FlattenKeys(sep string) (*Value, error) {
+	if v.jsonType != Object && v.jsonType != Array {
+		return &Value{}, v.typeError(Object, Array)
+	}
+	result := NewObject()
+	if len(v.objectValue) > 0 || len(v.arrayValue) > 0 {
+		v.flatten(result, nil, sep)
+	}
+	return result, nil
+}
+
+// Adds the leaves under a value to a flat object, where path is the keys
+// leading to the value.
+func (v *Value) flatten(result *Value, path []string, sep string) {
+	switch {
+	case v.jsonType == Object && len(v.objectValue) > 0:
+		for _, p := range v.objectValue {
+			p.val.flatten(result, append(path, p.key), sep)
+		}
+	case v.jsonType == Array && len(v.arrayValue) > 0:
+		for i, val := range v.arrayValue {
+			val.flatten(result, append(path, strconv.Itoa(i)), sep)
+		}
+	default:
+		result.objectValue = append(result.objectValue, pair{key: strings.Join(path, sep), val: v.Clone()})
+	}
+}
+
+// Reverses FlattenKeys, making a nested object from a single-level one by
+// splitting each key on sep. Parts of keys that are array indexes make
+// arrays, which are extended with nulls if indexes are missing, up to 1024
+// at a time, and the rest make objects, the same as in SetPointer. The
+// result is always an object, so a flattened array comes back as an object
+// keyed by index. The members are copied so that the original is left
+// unchanged. An empty sep doesn't split the keys at all.
+//
+// Returns ErrType if the value is not an object, or if a key needs to nest
+// members under another key that holds a scalar, as in {"a": 1, "a.b": 2},
+// ErrNotFound if it needs to nest them in an array under a key that isn't an
+// index, and ErrRange if an index is too far past the end of its array.
+func (v *Value) UnflattenKeys(sep string) (*Value, error) {
+	if v.jsonType != Object {
+		return &Value{}, v.typeError(Object)
+	}
+	result := NewObject()
+	for _, p := range v.objectValue {
+		path := []string{p.key}
+		if sep != "" {
+			path = strings.Split(p.key, sep)
+		}
+		if err := result.setPath(path, p.val.Clone()); err != nil {
+			return &Value{}, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlattenKeys(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		sep      string
+		expected string
+	}{
+		{`{}`, ".", `{}`},
+		{`[]`, ".", `{}`},
+		{`{"a": 1}`, ".", `{"a": 1}`},
+		{`{"a": {"b": 1, "c": {"d": null}}, "e": "f"}`, ".", `{"a.b": 1, "a.c.d": null, "e": "f"}`},
+		{`{"a": [true, {"b": 2}, [3]]}`, ".", `{"a.0": true, "a.1.b": 2, "a.2.0": 3}`},
+		{`{"a": {}, "b": [], "c": {"d": []}}`, ".", `{"a": {}, "b": [], "c.d": []}`},
+		{`["x", "y"]`, ".", `{"0": "x", "1": "y"}`},
+		{`{"a": {"b": 1}}`, "__", `{"a__b": 1}`},
+		{`{"a": {"b": 1}, "a": {"c": 2}}`, ".", `{"a.b": 1, "a.c": 2}`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			actual, err := val.FlattenKeys(test.sep)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.expected); actual.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if val.String() != MustParse(test.input).String() {
+				t.Errorf("expected %v got %v", test.input, val)
+			}
+		})
+	}
+
+	if _, err := NewString("a").FlattenKeys("."); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestUnflattenKeys(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		sep      string
+		expected string
+	}{
+		{`{}`, ".", `{}`},
+		{`{"a.b": 1, "a.c.d": null, "e": "f"}`, ".", `{"a": {"b": 1, "c": {"d": null}}, "e": "f"}`},
+		{`{"a.0": true, "a.1.b": 2, "a.2.0": 3}`, ".", `{"a": [true, {"b": 2}, [3]]}`},
+		{`{"a.1": "y", "a.0": "x"}`, ".", `{"a": ["x", "y"]}`},
+		{`{"a.2": 1}`, ".", `{"a": [null, null, 1]}`},
+		{`{"a": {}, "b.c": []}`, ".", `{"a": {}, "b": {"c": []}}`},
+		{`{"0": "x"}`, ".", `{"0": "x"}`},
+		{`{"a__b": 1, "a.c": 2}`, "__", `{"a": {"b": 1}, "a.c": 2}`},
+		{`{"a.b": 1}`, "", `{"a.b": 1}`},
+		{`{"a.b": 1, "a": 2}`, ".", `{"a": 2}`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			actual, err := val.UnflattenKeys(test.sep)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.expected); actual.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if val.String() != MustParse(test.input).String() {
+				t.Errorf("expected %v got %v", test.input, val)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		input *Value
+		err   error
+	}{
+		{NewArray(), ErrType},
+		{MustParse(`{"a": 1, "a.b": 2}`), ErrType},
+		{MustParse(`{"a.0": 1, "a.b": 2}`), ErrNotFound},
+		{MustParse(`{"a.999999999999": 1}`), ErrRange},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			if _, err := test.input.UnflattenKeys("."); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	val := MustParse(`{"a": {"b": [1, {"c": "d"}, []]}, "e": {}, "f": null}`)
+	flat, _ := val.FlattenKeys("/")
+	actual, err := flat.UnflattenKeys("/")
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if !Equal(val, actual) {
+		t.Errorf("expected %v got %v", val, actual)
+	}
+}
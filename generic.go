@@ -0,0 +1,55 @@
+package json
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Extracts a value from the JSON as the Go type T, using the accessor for
+// that type: string (AsString), int64 (AsInteger), float64 (AsNumber),
+// bool (AsBoolean), *big.Int (AsBigInt), []*Value (AsArray) and
+// map[string]*Value (AsObject). Returns ErrType if the value doesn't have
+// the matching JSON type or T is not one of those types, and any other
+// error the accessor returns.
+func As[T any](v *Value) (T, error) {
+	var t T
+	var err error
+	switch p := any(&t).(type) {
+	case *string:
+		*p, err = v.AsString()
+	case *int64:
+		*p, err = v.AsInteger()
+	case *float64:
+		*p, err = v.AsNumber()
+	case *bool:
+		*p, err = v.AsBoolean()
+	case **big.Int:
+		*p, err = v.AsBigInt()
+	case *[]*Value:
+		*p, err = v.AsArray()
+	case *map[string]*Value:
+		*p, err = v.AsObject()
+	default:
+		err = fmt.Errorf("%w: cannot extract %T from a value", ErrType, t)
+	}
+	return t, err
+}
+
+// Extracts an object member as the Go type T. See As for the supported types.
+// Returns ErrType if the value is not an object, ErrNotFound if the key is
+// missing, and any error As returns for the member.
+func GetAs[T any](v *Value, key string) (T, error) {
+	var t T
+	if v.jsonType != Object {
+		return t, v.typeError(Object)
+	}
+	val, ok := v.lookup(key)
+	if !ok {
+		return t, fmt.Errorf("%w: key %q", ErrNotFound, key)
+	}
+	t, err := As[T](val)
+	if err != nil {
+		return t, fmt.Errorf("%w at %q", err, formatPointer([]string{key}))
+	}
+	return t, nil
+}
@@ -0,0 +1,86 @@
+package json
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAs(t *testing.T) {
+	v, _ := ParseString(`["a", 5, 2.5, true, [1], {"b": 2}, 123456789012345678901234567890, null]`)
+
+	if s, err := As[string](v.Index(0)); s != "a" || err != nil {
+		t.Errorf("expected %v got %v %v", "a", s, err)
+	}
+	if i, err := As[int64](v.Index(1)); i != 5 || err != nil {
+		t.Errorf("expected %v got %v %v", 5, i, err)
+	}
+	if f, err := As[float64](v.Index(2)); f != 2.5 || err != nil {
+		t.Errorf("expected %v got %v %v", 2.5, f, err)
+	}
+	if f, err := As[float64](v.Index(1)); f != 5 || err != nil {
+		t.Errorf("expected %v got %v %v", 5, f, err)
+	}
+	if b, err := As[bool](v.Index(3)); b != true || err != nil {
+		t.Errorf("expected %v got %v %v", true, b, err)
+	}
+	if a, err := As[[]*Value](v.Index(4)); len(a) != 1 || err != nil {
+		t.Errorf("expected %v got %v %v", "[1]", a, err)
+	}
+	if m, err := As[map[string]*Value](v.Index(5)); len(m) != 1 || err != nil {
+		t.Errorf("expected %v got %v %v", `{"b": 2}`, m, err)
+	}
+	expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if b, err := As[*big.Int](v.Index(6)); err != nil || b.Cmp(expected) != 0 {
+		t.Errorf("expected %v got %v %v", expected, b, err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"wrong type", second(As[string](v.Index(1))), ErrType},
+		{"null", second(As[bool](v.Index(7))), ErrType},
+		{"overflow", second(As[int64](v.Index(6))), ErrRange},
+		{"unsupported", second(As[int](v.Index(1))), ErrType},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if !errors.Is(test.err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, test.err)
+			}
+		})
+	}
+}
+
+func TestGetAs(t *testing.T) {
+	v, _ := ParseString(`{"name": "app", "timeout": 10}`)
+
+	if s, err := GetAs[string](v, "name"); s != "app" || err != nil {
+		t.Errorf("expected %v got %v %v", "app", s, err)
+	}
+	if i, err := GetAs[int64](v, "timeout"); i != 10 || err != nil {
+		t.Errorf("expected %v got %v %v", 10, i, err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"wrong type", second(GetAs[bool](v, "name")), ErrType},
+		{"missing", second(GetAs[string](v, "missing")), ErrNotFound},
+		{"not object", second(GetAs[string](NewArray(), "name")), ErrType},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if !errors.Is(test.err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, test.err)
+			}
+		})
+	}
+}
+
+// Gets the error from a two value return.
+func second[T any](_ T, err error) error {
+	return err
+}
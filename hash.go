@@ -0,0 +1,92 @@
+package json
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+)
+
+// Gets a hash of the value's contents, such as for keying a cache by a
+// document without serializing it. Values that are equal according to Equal
+// have the same hash, so the order of an object's members doesn't matter, and
+// only the last value of any duplicate key counts. Different values almost
+// always have different hashes, but since a hash is only 64 bits, two can
+// collide, so use Equal to be sure. Hashes are the same from run to run.
+func (v *Value) Hash() uint64 {
+	h := fnv.New64a()
+	v.hash(h)
+	return h.Sum64()
+}
+
+// Writes the value's contents to a hash. Every value is written with its type
+// first and its length if it has one, so that no two values write the same
+// bytes.
+func (v *Value) hash(h hash.Hash64) {
+	var buf [9]byte
+	buf[0] = byte(v.jsonType)
+	switch v.jsonType {
+	case Boolean:
+		if v.booleanValue {
+			buf[1] = 1
+		}
+		h.Write(buf[:2])
+	case Integer:
+		i := v.integerValue
+		if v.bigValue != nil {
+			if !v.bigValue.IsInt64() {
+				// Set apart from int64s, whose bytes could be the same
+				buf[0] |= 0x80
+				buf[1] = byte(v.bigValue.Sign() + 1)
+				h.Write(buf[:2])
+				h.Write(v.bigValue.Bytes())
+				return
+			}
+			i = v.bigValue.Int64()
+		}
+		binary.BigEndian.PutUint64(buf[1:], uint64(i))
+		h.Write(buf[:])
+	case Number:
+		f := v.numberValue
+		if f == 0 {
+			// -0 equals 0
+			f = 0
+		}
+		binary.BigEndian.PutUint64(buf[1:], math.Float64bits(f))
+		h.Write(buf[:])
+	case String:
+		hashString(h, v.stringValue)
+	case Array:
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(v.arrayValue)))
+		h.Write(buf[:])
+		for _, val := range v.arrayValue {
+			val.hash(h)
+		}
+	case Object:
+		// Members are hashed separately and summed, which doesn't depend on
+		// their order
+		m, _ := v.AsObject()
+		var sum uint64
+		for k, val := range m {
+			mh := fnv.New64a()
+			hashString(mh, k)
+			val.hash(mh)
+			sum += mh.Sum64()
+		}
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(m)))
+		h.Write(buf[:])
+		binary.BigEndian.PutUint64(buf[1:], sum)
+		h.Write(buf[1:])
+	default:
+		h.Write(buf[:1])
+	}
+}
+
+// Writes a string to a hash the same way as a String value.
+func hashString(h hash.Hash64, s string) {
+	var buf [9]byte
+	buf[0] = byte(String)
+	binary.BigEndian.PutUint64(buf[1:], uint64(len(s)))
+	h.Write(buf[:])
+	h.Write([]byte(s))
+}
@@ -0,0 +1,57 @@
+package json
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	for _, test := range []struct {
+		a        *Value
+		b        *Value
+		expected bool
+	}{
+		{NewNull(), NewNull(), true},
+		{NewNull(), NewObject().Key("missing"), true},
+		{NewBool(true), NewBool(true), true},
+		{NewBool(true), NewBool(false), false},
+		{NewInt(5), NewInt(5), true},
+		{NewInt(5), NewInt(6), false},
+		{NewInt(5), NewNumber(5), false},
+		{NewInt(5), &Value{jsonType: Integer, bigValue: big.NewInt(5)}, true},
+		{MustParse(`123456789012345678901234567890`), MustParse(`123456789012345678901234567890`), true},
+		{MustParse(`123456789012345678901234567890`), MustParse(`-123456789012345678901234567890`), false},
+		{MustParse(`9223372036854775808`), NewInt(math.MinInt64), false},
+		{NewNumber(0.5), NewNumber(0.5), true},
+		{NewNumber(0), NewNumber(math.Copysign(0, -1)), true},
+		{NewNumber(0.5), NewNumber(0.25), false},
+		{NewString("a"), NewString("a"), true},
+		{NewString("a"), NewString("b"), false},
+		{NewString(""), NewNull(), false},
+		{MustParse(`["a", "b"]`), MustParse(`["a", "b"]`), true},
+		{MustParse(`["a", "b"]`), MustParse(`["b", "a"]`), false},
+		{MustParse(`["ab"]`), MustParse(`["a", "b"]`), false},
+		{MustParse(`[[], []]`), MustParse(`[[[]]]`), false},
+		{MustParse(`{"a": 1, "b": [2]}`), MustParse(`{"b": [2], "a": 1}`), true},
+		{MustParse(`{"a": 1, "a": 2}`), MustParse(`{"a": 2}`), true},
+		{MustParse(`{"a": 1}`), MustParse(`{"a": 2}`), false},
+		{MustParse(`{"a": 1}`), MustParse(`{"b": 1}`), false},
+		{MustParse(`{"a": "b"}`), MustParse(`["a", "b"]`), false},
+		{MustParse(`{"a": {"b": 1}}`), MustParse(`{"a": {"b": 1}, "c": {}}`), false},
+	} {
+		t.Run(test.a.String()+" "+test.b.String(), func(t *testing.T) {
+			if Equal(test.a, test.b) != test.expected {
+				t.Fatalf("expected %v got %v", test.expected, !test.expected)
+			}
+			if actual := test.a.Hash() == test.b.Hash(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	// Hashes don't change from run to run
+	if actual := MustParse(`{"a": [1, 2.5, "x", true, null]}`).Hash(); actual != 0xcb60838f30c78eb2 {
+		t.Errorf("expected %#x got %#x", uint64(0xcb60838f30c78eb2), actual)
+	}
+}
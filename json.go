@@ -1,9 +1,14 @@
 package json
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -11,6 +16,28 @@ var (
 	ErrType = errors.New("type error")
 	// A problem occured while parsing the JSON
 	ErrParse = errors.New("parse error")
+	// An array index is negative or past the end of the array,
+	// or a number doesn't fit in the requested type
+	ErrRange = errors.New("out of range")
+	// An object member being looked up doesn't exist
+	ErrNotFound = errors.New("member not found")
+	// A JSON Pointer string is not well-formed
+	ErrPointer = errors.New("malformed JSON pointer")
+	// A Query expression is not well-formed
+	ErrQuery = errors.New("malformed query")
+	// A closing brace or bracket doesn't match the array or object it closes.
+	// Wraps ErrParse.
+	ErrUnmatchedBrace = fmt.Errorf("%w: unmatched closing brace", ErrParse)
+	// Arrays and objects are nested more deeply than allowed. Wraps ErrParse.
+	ErrDepthExceeded = fmt.Errorf("%w: max depth exceeded", ErrParse)
+	// The input is not valid UTF-8. Wraps ErrParse.
+	ErrInvalidUTF8 = fmt.Errorf("%w: invalid UTF-8", ErrParse)
+	// The input ended before a whole value was read. Wraps ErrParse.
+	ErrUnexpectedEOF = fmt.Errorf("%w: unexpected end of input", ErrParse)
+	// The input is longer than the parser's size limit allows. Wraps ErrParse.
+	ErrTooLarge = fmt.Errorf("%w: input exceeds size limit", ErrParse)
+	// An object has the same key more than once where that isn't allowed
+	ErrDuplicateKey = errors.New("duplicate key")
 )
 
 // The type of a JSON value.
@@ -52,10 +79,24 @@ type Value struct {
 	jsonType     Type
 	numberValue  float64
 	integerValue int64
+	bigValue     *big.Int
+	decimalValue *big.Rat
+	rawNumber    string
 	stringValue  string
 	booleanValue bool
 	arrayValue   []*Value
 	objectValue  []pair
+	comments     []string
+	source       span
+	missing      bool // made up by the fluent interface for a miss
+}
+
+// Where a parsed value was found in the input.
+type span struct {
+	start  int
+	end    int
+	line   int
+	column int
 }
 
 type pair struct {
@@ -63,6 +104,67 @@ type pair struct {
 	val *Value
 }
 
+// Creates a null value.
+func NewNull() *Value {
+	return &Value{jsonType: Null}
+}
+
+// Creates a number value.
+func NewNumber(f float64) *Value {
+	return &Value{jsonType: Number, numberValue: f}
+}
+
+// Creates an integer value.
+func NewInt(i int64) *Value {
+	return &Value{jsonType: Integer, integerValue: i}
+}
+
+// Creates a string value.
+func NewString(s string) *Value {
+	return &Value{jsonType: String, stringValue: s}
+}
+
+// Creates a boolean value.
+func NewBool(b bool) *Value {
+	return &Value{jsonType: Boolean, booleanValue: b}
+}
+
+// Creates a string value holding binary data in standard base64, the
+// same way Marshal and encoding/json write a []byte.
+func NewBytes(b []byte) *Value {
+	return NewString(base64.StdEncoding.EncodeToString(b))
+}
+
+// Creates an array value containing the given values in order.
+func NewArray(vals ...*Value) *Value {
+	return &Value{jsonType: Array, arrayValue: append([]*Value{}, vals...)}
+}
+
+// Creates an empty object value.
+func NewObject() *Value {
+	return &Value{jsonType: Object, objectValue: []pair{}}
+}
+
+// Creates the null value the fluent interface returns for a member that
+// doesn't exist.
+func missingValue() *Value {
+	return &Value{missing: true}
+}
+
+// Creates an ErrType error saying what type the value was expected to have.
+// The value itself is left out, since it may be very large. A value that is
+// missing says so, so it can't be mistaken for a null in the input.
+func (v *Value) typeError(expected ...Type) error {
+	names := make([]string, len(expected))
+	for i, t := range expected {
+		names[i] = t.String()
+	}
+	if v.missing {
+		return fmt.Errorf("%w: expected %s, got missing value", ErrType, strings.Join(names, " or "))
+	}
+	return fmt.Errorf("%w: expected %s, got %v", ErrType, strings.Join(names, " or "), v.Type())
+}
+
 // Gets the type of the current value.
 func (v *Value) Type() Type {
 	if v.jsonType >= 0 && v.jsonType < numTypes {
@@ -71,12 +173,78 @@ func (v *Value) Type() Type {
 	return typeUnknown
 }
 
+// Gets the comments that came right before the value in the source, if it was
+// parsed with PreserveComments set. Each comment is returned exactly as it was
+// written, including its // or /* */ delimiters, so its style can be told apart.
+func (v *Value) Comments() []string {
+	return v.comments
+}
+
+// Gets the byte offsets in the source where a parsed value starts and
+// ends, so that the value was read from input[start:end]. The span covers
+// the whole value, including the quotes of a string and all the members of
+// an array or object, but not any whitespace or comments around it. Values
+// that weren't parsed, or were parsed with Valid, return 0 for both.
+func (v *Value) Span() (start, end int) {
+	return v.source.start, v.source.end
+}
+
+// Gets the line and column where a parsed value starts in the source, both
+// starting from 1 and with columns counted in runes, the same as in
+// ParseError. Values that weren't parsed return 0 for both.
+func (v *Value) Position() (line, column int) {
+	return v.source.line, v.source.column
+}
+
+// Reports whether the value is null. Since the fluent interface returns null
+// for missing members, this also reports whether a path didn't exist.
+func (v *Value) IsNull() bool {
+	return v.jsonType == Null
+}
+
+// Reports whether the value is the null that Key, Index or Get returns for a
+// member that doesn't exist, as opposed to a null that is really there.
+func (v *Value) IsMissing() bool {
+	return v.missing
+}
+
+// Reports whether the value is an object.
+func (v *Value) IsObject() bool {
+	return v.jsonType == Object
+}
+
+// Reports whether the value is an array.
+func (v *Value) IsArray() bool {
+	return v.jsonType == Array
+}
+
+// Reports whether the value is a string.
+func (v *Value) IsString() bool {
+	return v.jsonType == String
+}
+
+// Reports whether the value is a number or an integer, which is
+// whenever AsNumber would succeed.
+func (v *Value) IsNumber() bool {
+	return v.jsonType == Number || v.jsonType == Integer
+}
+
+// Reports whether the value is an integer.
+func (v *Value) IsInteger() bool {
+	return v.jsonType == Integer
+}
+
+// Reports whether the value is a boolean.
+func (v *Value) IsBool() bool {
+	return v.jsonType == Boolean
+}
+
 // Extracts a null value from the JSON. Returns ErrType if the value is not null, nil otherwise.
 func (v *Value) AsNull() (struct{}, error) {
 	if v.jsonType == Null {
 		return struct{}{}, nil
 	}
-	return struct{}{}, fmt.Errorf("%w: value not null %v", ErrType, v)
+	return struct{}{}, v.typeError(Null)
 }
 
 // Extracts a number from the JSON. If the value is an integer, it is cast to a float64. If integer
@@ -84,22 +252,128 @@ func (v *Value) AsNull() (struct{}, error) {
 // an integer. Returns nil otherwise.
 func (v *Value) AsNumber() (float64, error) {
 	if v.jsonType == Integer {
+		if v.bigValue != nil {
+			f, _ := new(big.Float).SetInt(v.bigValue).Float64()
+			return f, nil
+		}
 		return float64(v.integerValue), nil
 	}
 	if v.jsonType == Number {
 		return v.numberValue, nil
 	}
-	return 0, fmt.Errorf("%w: value not a valid number %v", ErrType, v)
+	return 0, v.typeError(Number, Integer)
+}
+
+// Extracts a number from the JSON, like AsNumber, and checks that it is
+// between min and max inclusive, such as 0 and 100 for a percentage.
+// Returns ErrType if the value is neither a number nor an integer, and
+// ErrRange if it is outside the bounds or is NaN. Returns nil otherwise.
+func (v *Value) AsNumberInRange(min, max float64) (float64, error) {
+	f, err := v.AsNumber()
+	if err != nil {
+		return 0, err
+	}
+	if !(f >= min && f <= max) {
+		return 0, fmt.Errorf("%w: value %v out of range [%v,%v]", ErrRange, f, min, max)
+	}
+	return f, nil
 }
 
 // Extracts an integer from the JSON. Will not convert decimal to integer. If decimal precision is
 // needed, use AsNumber instead. Returns ErrType if the value is niether a number nor an integer.
-// Returns nil otherwise.
+// Returns ErrRange if the integer is too large to fit in an int64, in which case
+// AsBigInt can be used instead. Returns nil otherwise.
 func (v *Value) AsInteger() (int64, error) {
 	if v.jsonType == Integer {
+		if v.bigValue != nil {
+			return 0, fmt.Errorf("%w: integer overflows int64 %v", ErrRange, v)
+		}
 		return v.integerValue, nil
 	}
-	return 0, fmt.Errorf("%w: value not a valid integer %v", ErrType, v)
+	return 0, v.typeError(Integer)
+}
+
+// Extracts an integer from the JSON, like AsInteger, and checks that it is
+// between min and max inclusive, such as 0 and 65535 for a port number.
+// Returns ErrType if the value is not an integer, and ErrRange if it is
+// outside the bounds. Returns nil otherwise.
+func (v *Value) AsIntegerInRange(min, max int64) (int64, error) {
+	i, err := v.AsInteger()
+	if err != nil {
+		return 0, err
+	}
+	if i < min || i > max {
+		return 0, fmt.Errorf("%w: value %d out of range [%d,%d]", ErrRange, i, min, max)
+	}
+	return i, nil
+}
+
+// Extracts an integer from the JSON, converting numbers if needed, and reports
+// whether the conversion was exact. Integers within the range of an int64 and
+// numbers with no fractional part, such as 5.0, convert exactly. Other numbers
+// are truncated toward zero, and values outside the range of an int64 are
+// clamped to it. Returns 0 and false if the value is neither a number nor an
+// integer, or is NaN.
+func (v *Value) AsIntegerLossy() (int64, bool) {
+	switch v.jsonType {
+	case Integer:
+		if v.bigValue == nil {
+			return v.integerValue, true
+		}
+		if v.bigValue.Sign() < 0 {
+			return math.MinInt64, false
+		}
+		return math.MaxInt64, false
+	case Number:
+		f := v.numberValue
+		switch {
+		case math.IsNaN(f):
+			return 0, false
+		case f < math.MinInt64:
+			return math.MinInt64, false
+		case f >= math.MaxInt64:
+			// MaxInt64 rounds up to 2^63 as a float64, which is out of range
+			return math.MaxInt64, false
+		}
+		i := int64(f)
+		return i, float64(i) == f
+	}
+	return 0, false
+}
+
+// Extracts an integer of any size from the JSON. Returns ErrType if the value is not an
+// integer, nil otherwise.
+func (v *Value) AsBigInt() (*big.Int, error) {
+	if v.jsonType == Integer {
+		return v.bigInt(), nil
+	}
+	return nil, v.typeError(Integer)
+}
+
+// Gets an integer value as a new big.Int, regardless of its size.
+func (v *Value) bigInt() *big.Int {
+	if v.bigValue != nil {
+		return new(big.Int).Set(v.bigValue)
+	}
+	return big.NewInt(v.integerValue)
+}
+
+// Extracts the text of a number exactly as it appeared in the source, such as "1e3"
+// or "1.50". For numbers that weren't parsed, a valid JSON representation of the
+// number is returned instead. Returns ErrType if the value is niether a number nor
+// an integer, and ErrRange if it is NaN or infinite. Returns nil otherwise.
+func (v *Value) AsRawNumber() (string, error) {
+	if v.jsonType != Integer && v.jsonType != Number {
+		return "", v.typeError(Number, Integer)
+	}
+	if v.rawNumber != "" {
+		return v.rawNumber, nil
+	}
+	e := &encodeState{}
+	if err := e.writeNumber(v); err != nil {
+		return "", err
+	}
+	return e.String(), nil
 }
 
 // Extracts a string value from the JSON. Returns ErrType if the value is not string, nil otherwise.
@@ -107,7 +381,36 @@ func (v *Value) AsString() (string, error) {
 	if v.jsonType == String {
 		return v.stringValue, nil
 	}
-	return "", fmt.Errorf("%w: value not a valid string %v", ErrType, v)
+	return "", v.typeError(String)
+}
+
+// Extracts a timestamp from a string in RFC 3339 format, such as
+// "2006-01-02T15:04:05Z" or "2006-01-02T15:04:05.999+07:00", since JSON has
+// no type for times. Returns ErrType if the value is not a string, ErrParse if
+// the string is not an RFC 3339 timestamp, nil otherwise.
+func (v *Value) AsTime() (time.Time, error) {
+	if v.jsonType != String {
+		return time.Time{}, v.typeError(String)
+	}
+	t, err := time.Parse(time.RFC3339, v.stringValue)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not an RFC 3339 time", ErrParse, v.stringValue)
+	}
+	return t, nil
+}
+
+// Extracts binary data from a string in standard base64, the way Unmarshal
+// and encoding/json read a []byte. Returns ErrType if the value is not a
+// string, ErrParse if it is not valid base64, and nil otherwise.
+func (v *Value) AsBytes() ([]byte, error) {
+	if v.jsonType != String {
+		return nil, v.typeError(String)
+	}
+	b, err := base64.StdEncoding.DecodeString(v.stringValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 string: %v", ErrParse, err)
+	}
+	return b, nil
 }
 
 // Extracts a boolean value from the JSON. Returns ErrType if the value is not boolean, nil otherwise.
@@ -115,7 +418,7 @@ func (v *Value) AsBoolean() (bool, error) {
 	if v.jsonType == Boolean {
 		return v.booleanValue, nil
 	}
-	return false, fmt.Errorf("%w: value not a valid boolean %v", ErrType, v)
+	return false, v.typeError(Boolean)
 }
 
 // Extracts an array value from the JSON. Returns ErrType if the value is not array, nil otherwise.
@@ -123,32 +426,155 @@ func (v *Value) AsArray() ([]*Value, error) {
 	if v.jsonType == Array {
 		return v.arrayValue, nil
 	}
-	return nil, fmt.Errorf("%w: value not a valid array %v", ErrType, v)
+	return nil, v.typeError(Array)
 }
 
-// Extracts an object value from the JSON. Returns ErrType if the value is not object, nil otherwise.
+// Extracts an object value from the JSON. If the object has duplicate keys,
+// the last value for each key is kept. Returns ErrType if the value is not
+// object, nil otherwise.
 func (v *Value) AsObject() (map[string]*Value, error) {
-	if v.jsonType == Object {
-		m := map[string]*Value{}
-		for _, pair := range v.objectValue {
-			m[pair.key] = pair.val
+	return v.AsObjectWith(LastWins)
+}
+
+// How a map is made from an object that has the same key more than once.
+type DuplicatePolicy int
+
+// Possible duplicate policies
+const (
+	// Keep the last value for each key, as in AsObject.
+	LastWins DuplicatePolicy = iota
+	// Keep the first value for each key, as in Key and Pointer.
+	FirstWins
+	// Fail with ErrDuplicateKey.
+	DuplicateError
+)
+
+// Extracts an object value from the JSON like AsObject, resolving duplicate
+// keys according to the policy. Returns ErrType if the value is not object,
+// ErrDuplicateKey if a key is repeated and the policy is DuplicateError,
+// nil otherwise.
+func (v *Value) AsObjectWith(policy DuplicatePolicy) (map[string]*Value, error) {
+	if v.jsonType != Object {
+		return nil, v.typeError(Object)
+	}
+	m := make(map[string]*Value, len(v.objectValue))
+	for _, pair := range v.objectValue {
+		if _, ok := m[pair.key]; ok {
+			switch policy {
+			case FirstWins:
+				continue
+			case DuplicateError:
+				return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, pair.key)
+			}
+		}
+		m[pair.key] = pair.val
+	}
+	return m, nil
+}
+
+// A member of an object, as returned by AsOrderedObject.
+type Member struct {
+	Key   string
+	Value *Value
+}
+
+// Extracts an object value from the JSON as its members in the order they
+// appear in the source, including any duplicate keys. Changing the returned
+// slice doesn't change the object. Returns ErrType if the value is not object,
+// nil otherwise.
+func (v *Value) AsOrderedObject() ([]Member, error) {
+	if v.jsonType != Object {
+		return nil, v.typeError(Object)
+	}
+	members := make([]Member, len(v.objectValue))
+	for i, p := range v.objectValue {
+		members[i] = Member{Key: p.key, Value: p.val}
+	}
+	return members, nil
+}
+
+// Extracts a string value from the JSON, or returns def if the value is not a string.
+func (v *Value) StringOr(def string) string {
+	if s, err := v.AsString(); err == nil {
+		return s
+	}
+	return def
+}
+
+// Extracts an integer from the JSON, or returns def if the value is not an
+// integer or doesn't fit in an int64.
+func (v *Value) IntOr(def int64) int64 {
+	if i, err := v.AsInteger(); err == nil {
+		return i
+	}
+	return def
+}
+
+// Extracts a number from the JSON, or returns def if the value is neither a
+// number nor an integer. Integers are cast to a float64 like in AsNumber.
+func (v *Value) NumberOr(def float64) float64 {
+	if f, err := v.AsNumber(); err == nil {
+		return f
+	}
+	return def
+}
+
+// Extracts a boolean value from the JSON, or returns def if the value is not a boolean.
+func (v *Value) BoolOr(def bool) bool {
+	if b, err := v.AsBoolean(); err == nil {
+		return b
+	}
+	return def
+}
+
+// Converts a value of any type to text, such as for filling in a template,
+// unlike AsString, which fails for anything but a string. Strings are
+// returned as is, without quotes. Null is the empty string, and booleans are
+// true or false. Integers are written in full, and numbers are written in
+// fixed notation unless they're very large or small, as in Marshal, but
+// without a trailing .0, so 5.0 becomes 5. Exact decimals are written in full,
+// and NaN and infinities are NaN, +Inf and -Inf. Arrays and objects are their
+// compact JSON, as from JSON.
+func (v *Value) Coerce() string {
+	switch v.jsonType {
+	case Null:
+		return ""
+	case String:
+		return v.stringValue
+	case Boolean:
+		return strconv.FormatBool(v.booleanValue)
+	case Integer:
+		if v.bigValue != nil {
+			return v.bigValue.String()
+		}
+		return strconv.FormatInt(v.integerValue, 10)
+	case Number:
+		if v.decimalValue != nil {
+			return Decimal{v.decimalValue}.String()
+		}
+		if math.IsNaN(v.numberValue) || math.IsInf(v.numberValue, 0) {
+			return strconv.FormatFloat(v.numberValue, 'g', -1, 64)
 		}
-		return m, nil
+		return formatFloat(v.numberValue)
 	}
-	return nil, fmt.Errorf("%w: value not a valid array %v", ErrType, v)
+	return v.JSON()
 }
 
 // Returns a string representation of the values. NOT valid JSON!
+// Use JSON to get valid JSON as a string.
 func (v *Value) String() string {
 	switch v.jsonType {
 	case Null:
 		return "null"
 	case Integer:
+		if v.bigValue != nil {
+			return v.bigValue.String()
+		}
 		return strconv.FormatInt(v.integerValue, 10)
 	case Number:
 		return strconv.FormatFloat(v.numberValue, 'f', -1, 64)
 	case String:
-		return strconv.Quote(v.stringValue)
+		return quote(v.stringValue)
 	case Boolean:
 		if v.booleanValue {
 			return "true"
@@ -170,7 +596,7 @@ func (v *Value) String() string {
 			if i > 0 {
 				str += ", "
 			}
-			str += strconv.Quote(pair.key)
+			str += quote(pair.key)
 			str += ": "
 			str += pair.val.String()
 		}
@@ -180,34 +606,382 @@ func (v *Value) String() string {
 	return "<unknown>"
 }
 
+// Returns Go code that builds the value out of constructor calls, such as
+// json.NewArray(json.NewInt(1), json.NewString("a")), for printing with %#v.
+// Object members are added with SetKey, so only the first of any duplicate
+// keys is shown, and integers too big for NewInt are built with MustParse.
+func (v *Value) GoString() string {
+	var b strings.Builder
+	v.goString(&b)
+	return b.String()
+}
+
+// Writes the Go code that builds the value.
+func (v *Value) goString(b *strings.Builder) {
+	switch v.jsonType {
+	case Null:
+		b.WriteString("json.NewNull()")
+	case Integer:
+		if v.bigValue != nil {
+			fmt.Fprintf(b, "json.MustParse(%q)", v.bigValue.String())
+			return
+		}
+		fmt.Fprintf(b, "json.NewInt(%d)", v.integerValue)
+	case Number:
+		switch {
+		case math.IsNaN(v.numberValue):
+			b.WriteString("json.NewNumber(math.NaN())")
+		case math.IsInf(v.numberValue, 0):
+			fmt.Fprintf(b, "json.NewNumber(math.Inf(%d))", int(math.Copysign(1, v.numberValue)))
+		default:
+			fmt.Fprintf(b, "json.NewNumber(%s)", strconv.FormatFloat(v.numberValue, 'g', -1, 64))
+		}
+	case String:
+		fmt.Fprintf(b, "json.NewString(%q)", v.stringValue)
+	case Boolean:
+		fmt.Fprintf(b, "json.NewBool(%t)", v.booleanValue)
+	case Array:
+		b.WriteString("json.NewArray(")
+		for i, val := range v.arrayValue {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			val.goString(b)
+		}
+		b.WriteString(")")
+	case Object:
+		b.WriteString("json.NewObject()")
+		seen := map[string]bool{}
+		for _, p := range v.objectValue {
+			if seen[p.key] {
+				continue
+			}
+			seen[p.key] = true
+			fmt.Fprintf(b, ".SetKey(%q, ", p.key)
+			p.val.goString(b)
+			b.WriteString(")")
+		}
+	default:
+		b.WriteString("<unknown>")
+	}
+}
+
+// Quotes a string escaping it according to RFC 8259, the same way as Marshal
+// except that HTML characters are left as is.
+func quote(s string) string {
+	e := &encodeState{}
+	e.writeString(s)
+	return e.String()
+}
+
 // Fluent interface for accessing array members.
 // If the value is not an array, or the index is out of range,
-// it instead returns null.
+// it instead returns a null that IsMissing reports as missing.
 func (v *Value) Index(i int) *Value {
 	if v.jsonType != Array {
-		return &Value{}
+		return missingValue()
 	}
 
 	if i < 0 || i >= len(v.arrayValue) {
-		return &Value{}
+		return missingValue()
 	}
 
 	return v.arrayValue[i]
 }
 
+// Gets a member of an array, like Index but for code where a missing member
+// is a mistake. Returns ErrType if the value is not an array, and ErrRange if
+// the index is out of range.
+func (v *Value) IndexE(i int) (*Value, error) {
+	if v.jsonType != Array {
+		return &Value{}, v.typeError(Array)
+	}
+	if i < 0 || i >= len(v.arrayValue) {
+		return &Value{}, fmt.Errorf("%w: index %d with length %d", ErrRange, i, len(v.arrayValue))
+	}
+	return v.arrayValue[i], nil
+}
+
+// Fluent interface for getting the first member of an array, the same as
+// Index(0). If the value is not an array, or the array is empty, it instead
+// returns a null that IsMissing reports as missing.
+func (v *Value) First() *Value {
+	return v.Index(0)
+}
+
+// Fluent interface for getting the last member of an array. If the value is
+// not an array, or the array is empty, it instead returns a null that
+// IsMissing reports as missing.
+func (v *Value) Last() *Value {
+	return v.Index(len(v.arrayValue) - 1)
+}
+
 // Fluent interface for accessing object members.
 // If the value is not an object, or the key doesn't exist,
-// it instead returns null.
+// it instead returns a null that IsMissing reports as missing.
 func (v *Value) Key(k string) *Value {
 	if v.jsonType != Object {
-		return &Value{}
+		return missingValue()
 	}
 
+	if val, ok := v.lookup(k); ok {
+		return val
+	}
+
+	return missingValue()
+}
+
+// Gets the first object member with the given key, like Key but for code
+// where a missing member is a mistake. Returns ErrType if the value is not an
+// object, and ErrNotFound if it has no member with the key.
+func (v *Value) KeyE(k string) (*Value, error) {
+	if v.jsonType != Object {
+		return &Value{}, v.typeError(Object)
+	}
+	if val, ok := v.lookup(k); ok {
+		return val, nil
+	}
+	return &Value{}, fmt.Errorf("%w: key %q", ErrNotFound, k)
+}
+
+// Fluent interface for accessing nested members. Each string in the path is
+// an object key and each int is an array index, so v.Get("a", 0, "b") is the
+// same as v.Key("a").Index(0).Key("b"). If any part of the path doesn't
+// exist, or is neither a string nor an int, it instead returns a null that
+// IsMissing reports as missing.
+func (v *Value) Get(path ...any) *Value {
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			v = v.Key(p)
+		case int:
+			v = v.Index(p)
+		default:
+			return missingValue()
+		}
+	}
+	return v
+}
+
+// Finds the first object member with the given key.
+func (v *Value) lookup(k string) (*Value, bool) {
 	for _, p := range v.objectValue {
 		if p.key == k {
-			return p.val
+			return p.val, true
+		}
+	}
+	return nil, false
+}
+
+// Sets the value of an object member. If the key already exists, its value is
+// replaced in place, keeping its position. Otherwise the key is added to the end.
+// Any later duplicates of the key are removed. Returns ErrType if the value is
+// not an object, nil otherwise.
+func (v *Value) Set(k string, val *Value) error {
+	if v.jsonType != Object {
+		return v.typeError(Object)
+	}
+
+	for i, p := range v.objectValue {
+		if p.key == k {
+			v.objectValue[i].val = val
+			v.deleteFrom(i+1, k)
+			return nil
+		}
+	}
+
+	v.objectValue = append(v.objectValue, pair{key: k, val: val})
+	return nil
+}
+
+// Removes all members with the given key from an object.
+// Returns whether anything was removed.
+func (v *Value) Delete(k string) bool {
+	if v.jsonType != Object {
+		return false
+	}
+	return v.deleteFrom(0, k)
+}
+
+// Removes object members with the given key, starting at the given position.
+func (v *Value) deleteFrom(start int, k string) bool {
+	kept := v.objectValue[:start]
+	for _, p := range v.objectValue[start:] {
+		if p.key != k {
+			kept = append(kept, p)
+		}
+	}
+	removed := len(kept) != len(v.objectValue)
+	for i := len(kept); i < len(v.objectValue); i++ {
+		v.objectValue[i] = pair{}
+	}
+	v.objectValue = kept
+	return removed
+}
+
+// Reports whether the value is an object containing the given key.
+func (v *Value) Has(k string) bool {
+	if v.jsonType != Object {
+		return false
+	}
+
+	_, ok := v.lookup(k)
+	return ok
+}
+
+// Adds values to the end of an array. Returns ErrType if the value is not an
+// array, nil otherwise.
+func (v *Value) Append(vals ...*Value) error {
+	if v.jsonType != Array {
+		return v.typeError(Array)
+	}
+	v.arrayValue = append(v.arrayValue, vals...)
+	return nil
+}
+
+// Sets the value of an object member like Set, but returns the object so that
+// calls can be chained, as in NewObject().SetKey("a", NewInt(1)). It is meant
+// for building known-good documents such as test fixtures, so it panics if
+// the value is not an object.
+func (v *Value) SetKey(k string, val *Value) *Value {
+	if err := v.Set(k, val); err != nil {
+		panic(fmt.Sprintf("json: SetKey(%q): %v", k, err))
+	}
+	return v
+}
+
+// Adds values to the end of an array like Append, but returns the array so
+// that calls can be chained, as in NewArray().Add(NewInt(1)).Add(NewInt(2)).
+// It panics if the value is not an array. See SetKey.
+func (v *Value) Add(vals ...*Value) *Value {
+	if err := v.Append(vals...); err != nil {
+		panic(fmt.Sprintf("json: Add: %v", err))
+	}
+	return v
+}
+
+// Replaces the array member at the given index. Returns ErrType if the value
+// is not an array, ErrRange if the index is out of range, nil otherwise.
+func (v *Value) SetIndex(i int, val *Value) error {
+	if v.jsonType != Array {
+		return v.typeError(Array)
+	}
+	if i < 0 || i >= len(v.arrayValue) {
+		return fmt.Errorf("%w: index %d with length %d", ErrRange, i, len(v.arrayValue))
+	}
+	v.arrayValue[i] = val
+	return nil
+}
+
+// Removes the array member at the given index, shifting later members down.
+// Returns ErrType if the value is not an array, ErrRange if the index is out
+// of range, nil otherwise.
+func (v *Value) Remove(i int) error {
+	if v.jsonType != Array {
+		return v.typeError(Array)
+	}
+	if i < 0 || i >= len(v.arrayValue) {
+		return fmt.Errorf("%w: index %d with length %d", ErrRange, i, len(v.arrayValue))
+	}
+	copy(v.arrayValue[i:], v.arrayValue[i+1:])
+	v.arrayValue[len(v.arrayValue)-1] = nil
+	v.arrayValue = v.arrayValue[:len(v.arrayValue)-1]
+	return nil
+}
+
+// Gets the keys of an object in the order they appear in the source, including
+// any duplicates. Returns ErrType if the value is not an object, nil otherwise.
+func (v *Value) Keys() ([]string, error) {
+	if v.jsonType != Object {
+		return nil, v.typeError(Object)
+	}
+	keys := make([]string, len(v.objectValue))
+	for i, p := range v.objectValue {
+		keys[i] = p.key
+	}
+	return keys, nil
+}
+
+// Calls f for each member of an object in the order they appear in the source.
+// Iteration stops early if f returns false. Does nothing if the value is not
+// an object.
+func (v *Value) Range(f func(key string, val *Value) bool) {
+	if v.jsonType != Object {
+		return
+	}
+	for _, p := range v.objectValue {
+		if !f(p.key, p.val) {
+			return
+		}
+	}
+}
+
+// Calls fn for each member of an object in the order they appear in the
+// source, like Range, without building a map as AsObject does. Iteration
+// stops early if fn returns false. Returns ErrType if the value is not an
+// object, nil otherwise.
+func (v *Value) ForEach(fn func(key string, val *Value) bool) error {
+	if v.jsonType != Object {
+		return v.typeError(Object)
+	}
+	v.Range(fn)
+	return nil
+}
+
+// Makes a deep copy of the value, so that changes to the copy or any of its
+// children don't affect the original.
+func (v *Value) Clone() *Value {
+	c := *v
+	if v.comments != nil {
+		c.comments = append([]string{}, v.comments...)
+	}
+	if v.bigValue != nil {
+		c.bigValue = new(big.Int).Set(v.bigValue)
+	}
+	if v.arrayValue != nil {
+		c.arrayValue = make([]*Value, len(v.arrayValue))
+		for i, val := range v.arrayValue {
+			c.arrayValue[i] = val.Clone()
+		}
+	}
+	if v.objectValue != nil {
+		c.objectValue = make([]pair, len(v.objectValue))
+		for i, p := range v.objectValue {
+			c.objectValue[i] = pair{key: p.key, val: p.val.Clone()}
 		}
 	}
+	return &c
+}
 
-	return &Value{}
+// Converts a value to plain Go values for use with code that doesn't know
+// about this package: nil, bool, string, int64 for integers (or *big.Int when
+// it doesn't fit), float64 for numbers, []any and map[string]any. If an object
+// has duplicate keys, the last value for each key is kept, like in AsObject.
+func (v *Value) ToGo() any {
+	switch v.jsonType {
+	case Boolean:
+		return v.booleanValue
+	case String:
+		return v.stringValue
+	case Integer:
+		if v.bigValue != nil {
+			return new(big.Int).Set(v.bigValue)
+		}
+		return v.integerValue
+	case Number:
+		return v.numberValue
+	case Array:
+		a := make([]any, len(v.arrayValue))
+		for i, val := range v.arrayValue {
+			a[i] = val.ToGo()
+		}
+		return a
+	case Object:
+		m := make(map[string]any, len(v.objectValue))
+		for _, p := range v.objectValue {
+			m[p.key] = p.val.ToGo()
+		}
+		return m
+	}
+	return nil
 }
@@ -1,8 +1,15 @@
 package json
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestTypeStrings(t *testing.T) {
@@ -109,6 +116,61 @@ func TestAsInteger(t *testing.T) {
 	}
 }
 
+func TestAsInRange(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected int64
+		err      error
+	}{
+		{`80`, 80, nil},
+		{`0`, 0, nil},
+		{`65535`, 65535, nil},
+		{`70000`, 0, ErrRange},
+		{`-1`, 0, ErrRange},
+		{`99999999999999999999`, 0, ErrRange},
+		{`80.0`, 0, ErrType},
+		{`"80"`, 0, ErrType},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			actual, err := MustParse(test.input).AsIntegerInRange(0, 65535)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+	if _, err := NewInt(70000).AsIntegerInRange(0, 65535); err == nil || !strings.Contains(err.Error(), "value 70000 out of range [0,65535]") {
+		t.Errorf("expected %v got %v", "value 70000 out of range [0,65535]", err)
+	}
+
+	for _, test := range []struct {
+		val      *Value
+		expected float64
+		err      error
+	}{
+		{NewNumber(50.5), 50.5, nil},
+		{NewInt(100), 100, nil},
+		{NewNumber(0), 0, nil},
+		{NewNumber(100.1), 0, ErrRange},
+		{NewNumber(-0.5), 0, ErrRange},
+		{NewNumber(math.NaN()), 0, ErrRange},
+		{NewNumber(math.Inf(1)), 0, ErrRange},
+		{NewBool(true), 0, ErrType},
+	} {
+		t.Run(test.val.String(), func(t *testing.T) {
+			actual, err := test.val.AsNumberInRange(0, 100)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
 func TestAsString(t *testing.T) {
 	val := Value{jsonType: String, stringValue: "5"}
 	num, err := val.AsString()
@@ -177,6 +239,42 @@ func TestAsObject(t *testing.T) {
 	}
 }
 
+func TestAsObjectWith(t *testing.T) {
+	val := MustParse(`{"a": 1, "b": 2, "a": 3}`)
+	for _, test := range []struct {
+		policy DuplicatePolicy
+		a      int64
+		err    error
+	}{
+		{LastWins, 3, nil},
+		{FirstWins, 1, nil},
+		{DuplicateError, 0, ErrDuplicateKey},
+	} {
+		t.Run(fmt.Sprint(test.policy), func(t *testing.T) {
+			m, err := val.AsObjectWith(test.policy)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(m) != 2 {
+				t.Errorf("expected %v got %v", 2, len(m))
+			}
+			if actual := m["a"].IntOr(0); actual != test.a {
+				t.Errorf("expected %v got %v", test.a, actual)
+			}
+		})
+	}
+
+	if m, err := MustParse(`{"a": 1, "b": 2}`).AsObjectWith(DuplicateError); err != nil || len(m) != 2 {
+		t.Errorf("expected no error got %v", err)
+	}
+	if _, err := NewArray().AsObjectWith(FirstWins); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
 func TestString(t *testing.T) {
 	for _, test := range []struct {
 		input    Value
@@ -188,6 +286,7 @@ func TestString(t *testing.T) {
 		{Value{jsonType: Number, numberValue: -5.1}, `-5.1`},
 		{Value{jsonType: Number, numberValue: -5.12}, `-5.12`},
 		{Value{jsonType: String, stringValue: "-5.12"}, `"-5.12"`},
+		{Value{jsonType: String, stringValue: "a\x00\x1f\"\\\n\u007fé"}, "\"a\\u0000\\u001f\\\"\\\\\\n\u007fé\""},
 		{Value{jsonType: Boolean, booleanValue: true}, `true`},
 		{Value{jsonType: Boolean, booleanValue: false}, `false`},
 		{Value{jsonType: Array, arrayValue: []*Value{
@@ -202,6 +301,9 @@ func TestString(t *testing.T) {
 			{"c", &Value{jsonType: String, stringValue: "-5.12"}},
 			{"d", &Value{jsonType: Boolean, booleanValue: true}},
 		}}, `{"a": null, "b": -5, "c": "-5.12", "d": true}`},
+		{Value{jsonType: Object, objectValue: []pair{
+			{"\x01\"key\"", &Value{}},
+		}}, `{"\u0001\"key\"": null}`},
 		{Value{jsonType: numTypes, integerValue: -5}, `<unknown>`},
 	} {
 		t.Run(fmt.Sprintf("%v", test.input), func(t *testing.T) {
@@ -213,6 +315,34 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestGoString(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected string
+	}{
+		{NewNull(), `json.NewNull()`},
+		{NewInt(-5), `json.NewInt(-5)`},
+		{MustParse(`123456789012345678901234567890`), `json.MustParse("123456789012345678901234567890")`},
+		{NewNumber(2.5), `json.NewNumber(2.5)`},
+		{NewNumber(1e100), `json.NewNumber(1e+100)`},
+		{NewNumber(math.NaN()), `json.NewNumber(math.NaN())`},
+		{NewNumber(math.Inf(-1)), `json.NewNumber(math.Inf(-1))`},
+		{NewString("a\"\n"), `json.NewString("a\"\n")`},
+		{NewBool(true), `json.NewBool(true)`},
+		{NewArray(), `json.NewArray()`},
+		{MustParse(`[1, [null]]`), `json.NewArray(json.NewInt(1), json.NewArray(json.NewNull()))`},
+		{NewObject(), `json.NewObject()`},
+		{MustParse(`{"a": {"b": false}, "c": "d", "a": 1}`), `json.NewObject().SetKey("a", json.NewObject().SetKey("b", json.NewBool(false))).SetKey("c", json.NewString("d"))`},
+		{&Value{jsonType: numTypes}, `<unknown>`},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if actual := fmt.Sprintf("%#v", test.input); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
 func TestIndex(t *testing.T) {
 	val, err := ParseString(`[[[true, false]]]`)
 
@@ -252,6 +382,64 @@ func TestIndex(t *testing.T) {
 	}
 }
 
+func TestFirstLast(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		first string
+		last  string
+	}{
+		{`[1, 2, 3]`, `1`, `3`},
+		{`[[1], null]`, `[1]`, `null`},
+		{`["a"]`, `"a"`, `"a"`},
+		{`[]`, `null`, `null`},
+		{`{"a": 1}`, `null`, `null`},
+		{`"abc"`, `null`, `null`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			if actual := val.First().String(); actual != test.first {
+				t.Errorf("expected %v got %v", test.first, actual)
+			}
+			if actual := val.Last().String(); actual != test.last {
+				t.Errorf("expected %v got %v", test.last, actual)
+			}
+			if len(val.arrayValue) == 0 && (!val.First().IsMissing() || !val.Last().IsMissing()) {
+				t.Errorf("expected %v got %v", true, false)
+			}
+		})
+	}
+}
+
+func TestKeyEIndexE(t *testing.T) {
+	val := MustParse(`{"a": [1, {"b": null}], "a": 2}`)
+	for _, test := range []struct {
+		name     string
+		get      func() (*Value, error)
+		expected string
+		err      error
+	}{
+		{"key", func() (*Value, error) { return val.KeyE("a") }, `[1, {"b": null}]`, nil},
+		{"null member", func() (*Value, error) { return val.Key("a").Index(1).KeyE("b") }, `null`, nil},
+		{"missing key", func() (*Value, error) { return val.KeyE("c") }, `null`, ErrNotFound},
+		{"key of array", func() (*Value, error) { return val.Key("a").KeyE("b") }, `null`, ErrType},
+		{"index", func() (*Value, error) { return val.Key("a").IndexE(1) }, `{"b": null}`, nil},
+		{"index too large", func() (*Value, error) { return val.Key("a").IndexE(2) }, `null`, ErrRange},
+		{"negative index", func() (*Value, error) { return val.Key("a").IndexE(-1) }, `null`, ErrRange},
+		{"index of object", func() (*Value, error) { return val.IndexE(0) }, `null`, ErrType},
+		{"index of missing", func() (*Value, error) { return val.Key("c").IndexE(0) }, `null`, ErrType},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.get()
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if actual.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
 func TestKey(t *testing.T) {
 	val, err := ParseString(`{"a": {"b": {"c": true, "d":false}}}`)
 
@@ -290,3 +478,722 @@ func TestKey(t *testing.T) {
 		})
 	}
 }
+
+func TestConstructors(t *testing.T) {
+	for _, test := range []struct {
+		actual   *Value
+		expected *Value
+	}{
+		{NewNull(), &Value{}},
+		{NewNumber(-5.12), &Value{jsonType: Number, numberValue: -5.12}},
+		{NewInt(-5), &Value{jsonType: Integer, integerValue: -5}},
+		{NewString("-5.12"), &Value{jsonType: String, stringValue: "-5.12"}},
+		{NewBool(true), &Value{jsonType: Boolean, booleanValue: true}},
+		{NewArray(), &Value{jsonType: Array, arrayValue: []*Value{}}},
+		{NewArray(NewNull(), NewInt(-5)), &Value{jsonType: Array, arrayValue: []*Value{
+			{},
+			{jsonType: Integer, integerValue: -5},
+		}}},
+		{NewObject(), &Value{jsonType: Object, objectValue: []pair{}}},
+	} {
+		t.Run(test.expected.String(), func(t *testing.T) {
+			if !equals(test.actual, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, test.actual)
+			}
+		})
+	}
+
+	vals := []*Value{NewNull()}
+	arr := NewArray(vals...)
+	vals[0] = NewBool(true)
+	if arr.Index(0).Type() != Null {
+		t.Errorf("expected %v got %v", Null, arr.Index(0).Type())
+	}
+}
+
+func TestSet(t *testing.T) {
+	val, _ := ParseString(`{"a": 1, "b": 2, "a": 3}`)
+	if err := val.Set("b", NewString("x")); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if err := val.Set("c", NewBool(true)); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if err := val.Set("a", NewNull()); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := &Value{jsonType: Object, objectValue: []pair{
+		{"a", &Value{}},
+		{"b", &Value{jsonType: String, stringValue: "x"}},
+		{"c", &Value{jsonType: Boolean, booleanValue: true}},
+	}}
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+
+	if err := NewArray().Set("a", NewNull()); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	val, _ := ParseString(`{"a": 1, "b": 2, "a": 3}`)
+	if !val.Delete("a") {
+		t.Errorf("expected %v got %v", true, false)
+	}
+	if val.Delete("a") {
+		t.Errorf("expected %v got %v", false, true)
+	}
+	expected := &Value{jsonType: Object, objectValue: []pair{
+		{"b", &Value{jsonType: Integer, integerValue: 2}},
+	}}
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	if NewArray().Delete("a") {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
+
+func TestHas(t *testing.T) {
+	val, _ := ParseString(`{"a": null}`)
+	for _, test := range []struct {
+		val      *Value
+		key      string
+		expected bool
+	}{
+		{val, "a", true},
+		{val, "b", false},
+		{NewObject(), "a", false},
+		{NewArray(), "a", false},
+	} {
+		t.Run(test.key, func(t *testing.T) {
+			if actual := test.val.Has(test.key); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	val := NewArray(NewNull())
+	if err := val.Append(NewInt(1), NewBool(true)); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if err := val.Append(); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := &Value{jsonType: Array, arrayValue: []*Value{
+		{},
+		{jsonType: Integer, integerValue: 1},
+		{jsonType: Boolean, booleanValue: true},
+	}}
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	if err := NewObject().Append(NewNull()); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	val := NewObject().
+		SetKey("a", NewInt(1)).
+		SetKey("b", NewArray().Add(NewInt(1)).Add(NewString("x"), NewNull())).
+		SetKey("a", NewBool(true))
+	expected := MustParse(`{"a": true, "b": [1, "x", null]}`)
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"SetKey", func() { NewArray().SetKey("a", NewNull()) }},
+		{"Add", func() { NewObject().Add(NewNull()) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("expected panic got none")
+				}
+			}()
+			test.f()
+		})
+	}
+}
+
+func TestSetIndex(t *testing.T) {
+	val := NewArray(NewNull(), NewNull())
+	if err := val.SetIndex(1, NewInt(1)); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := &Value{jsonType: Array, arrayValue: []*Value{
+		{},
+		{jsonType: Integer, integerValue: 1},
+	}}
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	for _, i := range []int{-1, 2} {
+		if err := val.SetIndex(i, NewNull()); !errors.Is(err, ErrRange) {
+			t.Errorf("expected %v got %v", ErrRange, err)
+		}
+	}
+	if err := NewObject().SetIndex(0, NewNull()); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	val := NewArray(NewInt(0), NewInt(1), NewInt(2))
+	if err := val.Remove(1); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := &Value{jsonType: Array, arrayValue: []*Value{
+		{jsonType: Integer, integerValue: 0},
+		{jsonType: Integer, integerValue: 2},
+	}}
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	for _, i := range []int{-1, 2} {
+		if err := val.Remove(i); !errors.Is(err, ErrRange) {
+			t.Errorf("expected %v got %v", ErrRange, err)
+		}
+	}
+	if err := NewObject().Remove(0); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	val, _ := ParseString(`{"c": 1, "a": 2, "b": 3, "a": 4}`)
+	keys, err := val.Keys()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := []string{"c", "a", "b", "a"}
+	if fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("expected %v got %v", expected, keys)
+	}
+
+	keys, err = NewObject().Keys()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("expected %v got %v", []string{}, keys)
+	}
+
+	if _, err := NewArray().Keys(); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestRange(t *testing.T) {
+	val, _ := ParseString(`{"c": 1, "a": 2, "b": 3}`)
+	actual := ""
+	val.Range(func(key string, val *Value) bool {
+		actual += key + val.String()
+		return true
+	})
+	if actual != "c1a2b3" {
+		t.Errorf("expected %v got %v", "c1a2b3", actual)
+	}
+
+	actual = ""
+	val.Range(func(key string, val *Value) bool {
+		actual += key
+		return key != "a"
+	})
+	if actual != "ca" {
+		t.Errorf("expected %v got %v", "ca", actual)
+	}
+
+	NewArray(NewNull()).Range(func(key string, val *Value) bool {
+		t.Errorf("expected no call got %v", key)
+		return true
+	})
+}
+
+func TestForEach(t *testing.T) {
+	val, _ := ParseString(`{"c": 1, "a": 2, "b": 3, "a": 4}`)
+	actual := ""
+	err := val.ForEach(func(key string, val *Value) bool {
+		actual += key + val.String()
+		return true
+	})
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if actual != "c1a2b3a4" {
+		t.Errorf("expected %v got %v", "c1a2b3a4", actual)
+	}
+
+	actual = ""
+	val.ForEach(func(key string, val *Value) bool {
+		actual += key
+		return key != "a"
+	})
+	if actual != "ca" {
+		t.Errorf("expected %v got %v", "ca", actual)
+	}
+
+	err = NewArray(NewNull()).ForEach(func(key string, val *Value) bool {
+		t.Errorf("expected no call got %v", key)
+		return true
+	})
+	if !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		val.ForEach(func(key string, val *Value) bool { return true })
+	})
+	if allocs != 0 {
+		t.Errorf("expected %v got %v", 0, allocs)
+	}
+}
+
+func TestAsBigInt(t *testing.T) {
+	val := Value{jsonType: Integer, integerValue: 5}
+	i, err := val.AsBigInt()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if i.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("expected %v got %v", 5, i)
+	}
+
+	expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	val = Value{jsonType: Integer, bigValue: expected}
+	i, err = val.AsBigInt()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if i.Cmp(expected) != 0 {
+		t.Errorf("expected %v got %v", expected, i)
+	}
+	i.SetInt64(0)
+	if val.bigValue.Cmp(expected) != 0 {
+		t.Errorf("expected %v got %v", expected, val.bigValue)
+	}
+
+	if _, err := val.AsInteger(); !errors.Is(err, ErrRange) {
+		t.Errorf("expected %v got %v", ErrRange, err)
+	}
+	if f, _ := val.AsNumber(); f != 123456789012345678901234567890.0 {
+		t.Errorf("expected %v got %v", 123456789012345678901234567890.0, f)
+	}
+	if s := val.String(); s != "123456789012345678901234567890" {
+		t.Errorf("expected %v got %v", "123456789012345678901234567890", s)
+	}
+
+	val = Value{jsonType: Number, numberValue: 5}
+	if _, err = val.AsBigInt(); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestAsRawNumber(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected string
+	}{
+		{MustParse("1e3"), "1e3"},
+		{MustParse("1.50"), "1.50"},
+		{MustParse("-0.0"), "-0.0"},
+		{MustParse("-0"), "-0"},
+		{MustParse("123456789012345678901234567890"), "123456789012345678901234567890"},
+		{NewNumber(5), "5.0"},
+		{NewNumber(5.5), "5.5"},
+		{NewInt(5), "5"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			actual, err := test.input.AsRawNumber()
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if _, err := NewString("5").AsRawNumber(); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+	if _, err := NewNumber(math.NaN()).AsRawNumber(); !errors.Is(err, ErrRange) {
+		t.Errorf("expected %v got %v", ErrRange, err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	val, _ := ParseString(`{"a": [1, {"b": null}], "c": 123456789012345678901234567890, "d": 1.5e3}`)
+	clone := val.Clone()
+	if !equals(val, clone) {
+		t.Errorf("expected %v got %v", val, clone)
+	}
+	if raw, _ := clone.Key("d").AsRawNumber(); raw != "1.5e3" {
+		t.Errorf("expected %v got %v", "1.5e3", raw)
+	}
+
+	clone.Key("a").Index(1).Set("b", NewBool(true))
+	clone.Key("a").Append(NewNull())
+	clone.Set("e", NewNull())
+	clone.Key("c").bigValue.SetInt64(0)
+	expected, _ := ParseString(`{"a": [1, {"b": null}], "c": 123456789012345678901234567890, "d": 1.5e3}`)
+	if !equals(val, expected) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+
+	empty := NewArray()
+	if c := empty.Clone(); c.arrayValue == nil || len(c.arrayValue) != 0 {
+		t.Errorf("expected %v got %v", empty, c)
+	}
+}
+
+func TestAccessorDefaults(t *testing.T) {
+	cfg, _ := ParseString(`{"name": "app", "timeout": 10, "ratio": 0.5, "debug": true, "big": 123456789012345678901234567890, "none": null}`)
+
+	for _, test := range []struct {
+		name     string
+		actual   any
+		expected any
+	}{
+		{"string", cfg.Key("name").StringOr("default"), "app"},
+		{"string missing", cfg.Key("missing").StringOr("default"), "default"},
+		{"string wrong type", cfg.Key("timeout").StringOr("default"), "default"},
+		{"int", cfg.Key("timeout").IntOr(30), int64(10)},
+		{"int null", cfg.Key("none").IntOr(30), int64(30)},
+		{"int number", cfg.Key("ratio").IntOr(30), int64(30)},
+		{"int overflow", cfg.Key("big").IntOr(30), int64(30)},
+		{"number", cfg.Key("ratio").NumberOr(1), 0.5},
+		{"number from int", cfg.Key("timeout").NumberOr(1), 10.0},
+		{"number missing", cfg.Key("missing").Index(2).NumberOr(1), 1.0},
+		{"bool", cfg.Key("debug").BoolOr(false), true},
+		{"bool wrong type", cfg.Key("name").BoolOr(false), false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, test.actual)
+			}
+		})
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	exact := ParseOptions{ExactDecimals: true}
+	decimal, _ := ParseWithOptions(strings.NewReader(`0.10000000000000000001`), exact)
+	for _, test := range []struct {
+		input    *Value
+		expected string
+	}{
+		{NewNull(), ""},
+		{NewString(`a "b" <c>`), `a "b" <c>`},
+		{NewBool(true), "true"},
+		{NewBool(false), "false"},
+		{NewInt(-5), "-5"},
+		{MustParse(`123456789012345678901234567890`), "123456789012345678901234567890"},
+		{NewNumber(5), "5"},
+		{MustParse(`1.50e1`), "15"},
+		{NewNumber(0.1), "0.1"},
+		{NewNumber(1e21), "1e+21"},
+		{NewNumber(1.5e-7), "1.5e-7"},
+		{decimal, "0.10000000000000000001"},
+		{NewNumber(math.NaN()), "NaN"},
+		{NewNumber(math.Inf(1)), "+Inf"},
+		{NewNumber(math.Inf(-1)), "-Inf"},
+		{MustParse(`[1, "a", null]`), `[1,"a",null]`},
+		{MustParse(`{"a": {"b": 2.0}}`), `{"a":{"b":2.0}}`},
+		{NewObject().Key("missing"), ""},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if actual := test.input.Coerce(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAsTime(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected time.Time
+		err      error
+	}{
+		{NewString("2006-01-02T15:04:05Z"), time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), nil},
+		{NewString("2006-01-02T15:04:05.5+07:00"), time.Date(2006, 1, 2, 8, 4, 5, 500000000, time.UTC), nil},
+		{NewString("2006-01-02"), time.Time{}, ErrParse},
+		{NewString("Mon Jan 2 15:04:05 2006"), time.Time{}, ErrParse},
+		{NewString(""), time.Time{}, ErrParse},
+		{NewInt(1136214245), time.Time{}, ErrType},
+		{NewNull(), time.Time{}, ErrType},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			actual, err := test.input.AsTime()
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !actual.Equal(test.expected) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAsBytes(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected []byte
+		err      error
+	}{
+		{NewString("aGVsbG8="), []byte("hello"), nil},
+		{NewString(""), []byte{}, nil},
+		{NewBytes([]byte{0, 0xff, 0xfe}), []byte{0, 0xff, 0xfe}, nil},
+		{NewString("aGVsbG8"), nil, ErrParse},
+		{NewString("a-_b"), nil, ErrParse},
+		{NewInt(5), nil, ErrType},
+		{NewNull(), nil, ErrType},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			actual, err := test.input.AsBytes()
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !bytes.Equal(actual, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if actual := NewBytes([]byte("hello?>")).String(); actual != `"aGVsbG8/Pg=="` {
+		t.Errorf("expected %v got %v", `"aGVsbG8/Pg=="`, actual)
+	}
+}
+
+func TestAsIntegerLossy(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected int64
+		exact    bool
+	}{
+		{NewInt(5), 5, true},
+		{NewInt(math.MinInt64), math.MinInt64, true},
+		{NewNumber(5.0), 5, true},
+		{NewNumber(-0.0), 0, true},
+		{NewNumber(-3.0), -3, true},
+		{NewNumber(5.5), 5, false},
+		{NewNumber(-5.5), -5, false},
+		{NewNumber(-9223372036854775808), math.MinInt64, true},
+		{NewNumber(9223372036854775808), math.MaxInt64, false},
+		{NewNumber(-1e19), math.MinInt64, false},
+		{NewNumber(math.Inf(1)), math.MaxInt64, false},
+		{NewNumber(math.NaN()), 0, false},
+		{MustParse("123456789012345678901234567890"), math.MaxInt64, false},
+		{MustParse("-123456789012345678901234567890"), math.MinInt64, false},
+		{NewString("5"), 0, false},
+		{NewNull(), 0, false},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			actual, exact := test.input.AsIntegerLossy()
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if exact != test.exact {
+				t.Errorf("expected %v got %v", test.exact, exact)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	val := MustParse(`{"a": [{"b": "c"}, 5], "d": null}`)
+	for _, test := range []struct {
+		path     []any
+		expected string
+	}{
+		{[]any{}, val.String()},
+		{[]any{"a", 0, "b"}, `"c"`},
+		{[]any{"a", 1}, `5`},
+		{[]any{"a", 2}, `null`},
+		{[]any{"a", -1}, `null`},
+		{[]any{"a", "b"}, `null`},
+		{[]any{"x", 0, "b"}, `null`},
+		{[]any{"a", 0, "b", 0}, `null`},
+		{[]any{"a", 0.0}, `null`},
+		{[]any{"a", int64(0)}, `null`},
+	} {
+		t.Run(fmt.Sprint(test.path), func(t *testing.T) {
+			if actual := val.Get(test.path...).String(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if val.Get("a", 0, "b") != val.Key("a").Index(0).Key("b") {
+		t.Errorf("expected %v got %v", val.Key("a").Index(0).Key("b"), val.Get("a", 0, "b"))
+	}
+}
+
+func TestIsMissing(t *testing.T) {
+	val := MustParse(`{"a": [null], "b": null}`)
+	for _, test := range []struct {
+		name     string
+		val      *Value
+		expected bool
+	}{
+		{"null member", val.Key("b"), false},
+		{"null element", val.Key("a").Index(0), false},
+		{"null path", val.Get("a", 0), false},
+		{"missing key", val.Key("c"), true},
+		{"missing index", val.Key("a").Index(1), true},
+		{"not an array", val.Key("b").Index(0), true},
+		{"not an object", val.Key("a").Key("b"), true},
+		{"missing path", val.Get("c", 0, "d"), true},
+		{"bad path", val.Get(1.5), true},
+		{"parsed", MustParse(`null`), false},
+		{"created", NewNull(), false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.val.IsMissing(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if !test.val.IsNull() {
+				t.Errorf("expected %v got %v", true, false)
+			}
+			_, err := test.val.AsString()
+			if !errors.Is(err, ErrType) {
+				t.Errorf("expected %v got %v", ErrType, err)
+			}
+			if actual := strings.Contains(err.Error(), "missing"); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected [7]bool
+	}{
+		{NewNull(), [7]bool{true, false, false, false, false, false, false}},
+		{NewObject(), [7]bool{false, true, false, false, false, false, false}},
+		{NewArray(), [7]bool{false, false, true, false, false, false, false}},
+		{NewString("a"), [7]bool{false, false, false, true, false, false, false}},
+		{NewNumber(1.5), [7]bool{false, false, false, false, true, false, false}},
+		{NewInt(1), [7]bool{false, false, false, false, true, true, false}},
+		{NewBool(false), [7]bool{false, false, false, false, false, false, true}},
+		{&Value{jsonType: numTypes}, [7]bool{false, false, false, false, false, false, false}},
+	} {
+		t.Run(test.input.Type().String(), func(t *testing.T) {
+			actual := [7]bool{
+				test.input.IsNull(),
+				test.input.IsObject(),
+				test.input.IsArray(),
+				test.input.IsString(),
+				test.input.IsNumber(),
+				test.input.IsInteger(),
+				test.input.IsBool(),
+			}
+			if actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if !MustParse(`{"a": 1}`).Key("b").IsNull() {
+		t.Errorf("expected %v got %v", true, false)
+	}
+}
+
+func TestTypeErrorMessages(t *testing.T) {
+	big := NewArray()
+	for i := 0; i < 1000; i++ {
+		big.Append(NewString("a long string that should not show up in errors"))
+	}
+	for _, test := range []struct {
+		err      error
+		expected string
+	}{
+		{second(big.AsString()), "type error: expected <string>, got <array>"},
+		{second(big.AsObject()), "type error: expected <object>, got <array>"},
+		{second(big.AsNumber()), "type error: expected <number> or <integer>, got <array>"},
+		{second(NewObject().AsArray()), "type error: expected <array>, got <object>"},
+		{second(NewNumber(1.5).AsInteger()), "type error: expected <integer>, got <number>"},
+		{second(NewNull().AsBoolean()), "type error: expected <boolean>, got <null>"},
+		{second(NewInt(1).AsNull()), "type error: expected <null>, got <integer>"},
+		{big.Set("a", NewNull()), "type error: expected <object>, got <array>"},
+		{second(GetAs[string](MustParse(`{"a/b": 1}`), "a/b")), `type error: expected <string>, got <integer> at "/a~1b"`},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if !errors.Is(test.err, ErrType) {
+				t.Errorf("expected %v got %v", ErrType, test.err)
+			}
+			if test.err.Error() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, test.err)
+			}
+		})
+	}
+}
+
+func TestAsOrderedObject(t *testing.T) {
+	val := MustParse(`{"b": 1, "a": [2], "b": "3"}`)
+	actual, err := val.AsOrderedObject()
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := []Member{
+		{"b", NewInt(1)},
+		{"a", NewArray(NewInt(2))},
+		{"b", NewString("3")},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i].Key != expected[i].Key || !Equal(actual[i].Value, expected[i].Value) {
+			t.Errorf("expected %v got %v", expected[i], actual[i])
+		}
+	}
+
+	actual[0].Key = "c"
+	if keys, _ := val.Keys(); keys[0] != "b" {
+		t.Errorf("expected %v got %v", "b", keys[0])
+	}
+
+	if m, err := NewObject().AsOrderedObject(); len(m) != 0 || err != nil {
+		t.Errorf("expected %v got %v %v", "[]", m, err)
+	}
+	if _, err := NewArray().AsOrderedObject(); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestToGo(t *testing.T) {
+	val := MustParse(`{"n": null, "b": true, "i": -5, "f": 2.5, "s": "x", "a": [1, "y", []], "o": {"k": {}}, "d": 1, "d": 2}`)
+	expected := map[string]any{
+		"n": nil,
+		"b": true,
+		"i": int64(-5),
+		"f": 2.5,
+		"s": "x",
+		"a": []any{int64(1), "y", []any{}},
+		"o": map[string]any{"k": map[string]any{}},
+		"d": int64(2),
+	}
+	if actual := val.ToGo(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v got %v", expected, actual)
+	}
+
+	b, ok := MustParse(`123456789012345678901234567890`).ToGo().(*big.Int)
+	if !ok || b.String() != "123456789012345678901234567890" {
+		t.Errorf("expected %v got %v", "123456789012345678901234567890", b)
+	}
+	if f, ok := NewNumber(5).ToGo().(float64); !ok || f != 5 {
+		t.Errorf("expected %v got %v", 5.0, f)
+	}
+}
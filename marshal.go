@@ -0,0 +1,226 @@
+package json
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Builds a value from an arbitrary Go value using reflection, the reverse of
+// UnmarshalValue. Structs become objects with members named by their
+// `json:"name"` tags or field names, in field order. Fields tagged with
+// omitempty are left out if they hold false, 0, "", a nil pointer or
+// interface, or an empty slice, array or map. Maps become objects with
+// their keys sorted, and nil slices, maps and pointers become null.
+// A []byte becomes a base64 string, and types implementing
+// encoding.TextMarshaler, such as time.Time, become strings.
+//
+// Returns ErrType if the value contains something that can't be represented
+// as JSON, such as a channel or a function, or if it contains itself, such as
+// a struct with a pointer back to itself.
+func MarshalValue(v any) (*Value, error) {
+	return encode(reflect.ValueOf(v), "", map[visit]bool{})
+}
+
+// A pointer, map or slice being encoded. Slices are told apart by length
+// too, since a slice and a shorter slice of it share the same pointer.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// Builds a value from a reflect.Value. The path is a JSON Pointer
+// to the value being encoded, for use in error messages. Seen holds the
+// pointers, maps and slices that the value is inside of, so that a value
+// that contains itself is caught instead of being encoded forever.
+func encode(rv reflect.Value, path string, seen map[visit]bool) (*Value, error) {
+	if !rv.IsValid() {
+		return &Value{}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			break
+		}
+		v := visit{ptr: rv.Pointer(), typ: rv.Type()}
+		if rv.Kind() == reflect.Slice {
+			v.len = rv.Len()
+		}
+		if seen[v] {
+			return &Value{}, fmt.Errorf("%w: cannot marshal cycle of %v at %q", ErrType, rv.Type(), path)
+		}
+		seen[v] = true
+		defer delete(seen, v)
+	}
+
+	// Fields promoted from unexported embedded structs can't be used as interfaces
+	if rv.CanInterface() {
+		switch rv.Type() {
+		case valueType:
+			val := rv.Interface().(Value)
+			return &val, nil
+		case reflect.PtrTo(valueType):
+			if rv.IsNil() {
+				return &Value{}, nil
+			}
+			return rv.Interface().(*Value), nil
+		case bigIntType:
+			b := rv.Interface().(big.Int)
+			return newBigInt(&b), nil
+		case reflect.PtrTo(bigIntType):
+			if rv.IsNil() {
+				return &Value{}, nil
+			}
+			return newBigInt(rv.Interface().(*big.Int)), nil
+		}
+
+		if rv.Type().Implements(textMarshalerType) {
+			if rv.Kind() == reflect.Pointer && rv.IsNil() {
+				return &Value{}, nil
+			}
+			text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return &Value{}, fmt.Errorf("%w: cannot marshal %v at %q: %v", ErrType, rv.Type(), path, err)
+			}
+			return NewString(string(text)), nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return &Value{}, nil
+		}
+		return encode(rv.Elem(), path, seen)
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return newBigInt(new(big.Int).SetUint64(u)), nil
+		}
+		return NewInt(int64(u)), nil
+	case reflect.Float32, reflect.Float64:
+		return NewNumber(rv.Float()), nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return &Value{}, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return NewBytes(rv.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		arr := &Value{jsonType: Array, arrayValue: make([]*Value, rv.Len())}
+		for i := 0; i < rv.Len(); i++ {
+			val, err := encode(rv.Index(i), path+"/"+strconv.Itoa(i), seen)
+			if err != nil {
+				return &Value{}, err
+			}
+			arr.arrayValue[i] = val
+		}
+		return arr, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return &Value{}, nil
+		}
+		obj := NewObject()
+		for _, k := range rv.MapKeys() {
+			key, err := encodeMapKey(k)
+			if err != nil {
+				return &Value{}, fmt.Errorf("%w at %q", err, path)
+			}
+			val, err := encode(rv.MapIndex(k), path+formatPointer([]string{key}), seen)
+			if err != nil {
+				return &Value{}, err
+			}
+			obj.objectValue = append(obj.objectValue, pair{key: key, val: val})
+		}
+		sort.Slice(obj.objectValue, func(i, j int) bool {
+			return obj.objectValue[i].key < obj.objectValue[j].key
+		})
+		return obj, nil
+	case reflect.Struct:
+		obj := NewObject()
+		for _, f := range typeFields(rv.Type()) {
+			fv, ok := embeddedField(rv, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			val, err := encode(fv, path+formatPointer([]string{f.name}), seen)
+			if err != nil {
+				return &Value{}, err
+			}
+			obj.objectValue = append(obj.objectValue, pair{key: f.name, val: val})
+		}
+		return obj, nil
+	}
+	return &Value{}, fmt.Errorf("%w: cannot marshal %v at %q", ErrType, rv.Type(), path)
+}
+
+// Creates an integer value from a big.Int, using an int64 if it fits.
+func newBigInt(b *big.Int) *Value {
+	if b.IsInt64() {
+		return NewInt(b.Int64())
+	}
+	return &Value{jsonType: Integer, bigValue: new(big.Int).Set(b)}
+}
+
+// Gets a nested struct field for reading. Reports false if the field
+// is inside an embedded struct pointer that is nil.
+func embeddedField(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// Converts a map key to an object key.
+func encodeMapKey(k reflect.Value) (string, error) {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("%w: unsupported map key type %v", ErrType, k.Type())
+}
+
+// Whether a field tagged omitempty should be left out.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalValue(t *testing.T) {
+	type omit struct {
+		A bool           `json:"a,omitempty"`
+		B int            `json:"b,omitempty"`
+		C string         `json:"c,omitempty"`
+		D *int           `json:"d,omitempty"`
+		E []int          `json:"e,omitempty"`
+		F map[string]int `json:"f,omitempty"`
+		G float64        `json:"g,omitempty"`
+		H any            `json:"h,omitempty"`
+		I uint           `json:"i,omitempty"`
+		J [0]int         `json:"j,omitempty"`
+		K int            `json:"k"`
+	}
+	five := 5
+	for _, test := range []struct {
+		input    any
+		expected string
+	}{
+		{nil, `null`},
+		{true, `true`},
+		{"s", `"s"`},
+		{-5, `-5`},
+		{uint8(5), `5`},
+		{uint64(math.MaxUint64), `18446744073709551615`},
+		{1.5, `1.5`},
+		{float32(2), `2.0`},
+		{&five, `5`},
+		{(*int)(nil), `null`},
+		{[]int(nil), `null`},
+		{[]int{}, `[]`},
+		{[]any{1, "a", nil}, `[1,"a",null]`},
+		{[2]bool{true, false}, `[true,false]`},
+		{[]byte("hello"), `"aGVsbG8="`},
+		{[]byte(nil), `null`},
+		{map[string]int(nil), `null`},
+		{map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{map[int]bool{10: true, -1: false}, `{"-1":false,"10":true}`},
+		{map[uint]bool{1: true}, `{"1":true}`},
+		{time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), `"2006-01-02T15:04:05Z"`},
+		{big.NewInt(5), `5`},
+		{*bigIntFromString("123456789012345678901234567890"), `123456789012345678901234567890`},
+		{(*big.Int)(nil), `null`},
+		{NewArray(NewNull()), `[null]`},
+		{*NewBool(true), `true`},
+		{(*Value)(nil), `null`},
+		{(*time.Time)(nil), `null`},
+		{omit{}, `{"k":0}`},
+		{omit{A: true, B: 1, C: "c", D: &five, E: []int{1}, F: map[string]int{"x": 1}, G: 1, H: 0, I: 1}, `{"a":true,"b":1,"c":"c","d":5,"e":[1],"f":{"x":1},"g":1.0,"h":0,"i":1,"k":0}`},
+		{testStruct{
+			testEmbedded: testEmbedded{Embedded: "e", Shadowed: "hidden"},
+			Name:         "John",
+			Tags:         []string{"a"},
+			Shadowed:     7,
+			Skipped:      "no",
+			private:      "no",
+		}, `{"Embedded":"e","name":"John","Score":0.0,"Tags":["a"],"Counts":null,"IDs":null,"Nested":null,"Ratio":[0.0,0.0],"Any":null,"Raw":null,"Time":"0001-01-01T00:00:00Z","Bytes":null,"Shadowed":7}`},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			val, err := MarshalValue(test.input)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			actual, err := Marshal(val)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %v got %v", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestMarshalValueRoundTrip(t *testing.T) {
+	expected := testStruct{
+		testEmbedded: testEmbedded{Embedded: "e"},
+		Name:         "John",
+		Age:          30,
+		Score:        1.5,
+		Tags:         []string{"a", "b"},
+		Counts:       map[string]int{"x": 1, "y": 2},
+		IDs:          map[int]bool{1: true, -2: false},
+		Nested:       &testStruct{Name: "Paul"},
+		Ratio:        [2]float32{0.5, 0},
+		Any:          []any{int64(1), 2.5, "s", nil, map[string]any{"k": true}},
+		Time:         time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		Bytes:        []byte("hello"),
+		Shadowed:     7,
+	}
+	val, err := MarshalValue(expected)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	actual := testStruct{}
+	if err := UnmarshalValue(val, &actual); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	actual.Nested.Raw, actual.Raw = nil, nil
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v\ngot %+v", expected, actual)
+	}
+}
+
+func TestMarshalValueInvalid(t *testing.T) {
+	for _, input := range []any{
+		make(chan int),
+		func() {},
+		complex(1, 2),
+		map[bool]int{true: 1},
+		[]any{1, make(chan int)},
+		struct{ A map[string]func() }{map[string]func(){"a": nil}},
+	} {
+		t.Run(reflect.TypeOf(input).String(), func(t *testing.T) {
+			if _, err := MarshalValue(input); !errors.Is(err, ErrType) {
+				t.Errorf("expected %v got %v", ErrType, err)
+			}
+		})
+	}
+}
+
+type cyclicNode struct {
+	Name string
+	Next *cyclicNode `json:"next,omitempty"`
+}
+
+func TestMarshalValueCycle(t *testing.T) {
+	node := &cyclicNode{Name: "a", Next: &cyclicNode{Name: "b"}}
+	node.Next.Next = node
+	m := map[string]any{}
+	m["self"] = []any{m}
+	s := []any{1, nil}
+	s[1] = s
+
+	for _, test := range []struct {
+		name  string
+		input any
+		path  string
+	}{
+		{"struct", node, `"/next/next"`},
+		{"map", m, `"/self/0"`},
+		{"slice", s, `"/1"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := MarshalValue(test.input)
+			if !errors.Is(err, ErrType) {
+				t.Fatalf("expected %v got %v", ErrType, err)
+			}
+			if !strings.Contains(err.Error(), test.path) {
+				t.Errorf("expected %v got %v", test.path, err)
+			}
+		})
+	}
+
+	// The same value may appear more than once if it isn't inside itself
+	shared := &cyclicNode{Name: "c"}
+	val, err := MarshalValue([]*cyclicNode{shared, shared})
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if expected := `[{"Name":"c"},{"Name":"c"}]`; val.JSON() != expected {
+		t.Errorf("expected %v got %v", expected, val.JSON())
+	}
+}
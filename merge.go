@@ -0,0 +1,71 @@
+package json
+
+// Combines two objects into a new one, leaving both of them unchanged.
+// Members of override replace the members of base with the same key, and
+// members only in override are added after those of base. If deep is set,
+// a member that is an object in both is merged the same way rather than
+// replaced, but arrays and other values are always replaced. Unlike an
+// RFC 7386 merge patch, a null in override is kept as null rather than
+// removing the member.
+//
+// Returns ErrType if either value is not an object.
+func Merge(base, override *Value, deep bool) (*Value, error) {
+	if base.jsonType != Object {
+		return &Value{}, base.typeError(Object)
+	}
+	if override.jsonType != Object {
+		return &Value{}, override.typeError(Object)
+	}
+	return merge(base, override, deep), nil
+}
+
+// Merges two objects, which must both be objects.
+func merge(base, override *Value, deep bool) *Value {
+	result := base.Clone()
+	for _, p := range override.objectValue {
+		val := p.val.Clone()
+		if old, ok := result.lookup(p.key); deep && ok && old.jsonType == Object && p.val.jsonType == Object {
+			val = merge(old, p.val, deep)
+		}
+		result.Set(p.key, val)
+	}
+	return result
+}
+
+// Makes a new object with only the members of an object whose keys are
+// given, leaving the original unchanged. Members keep the order they have in
+// the original, and keys it doesn't have are ignored. If the object has
+// duplicate keys, every member with a given key is kept.
+//
+// Returns ErrType if the value is not an object.
+func (v *Value) Pick(keys ...string) (*Value, error) {
+	return v.filter(keys, true)
+}
+
+// Makes a new object with all the members of an object except those whose
+// keys are given, leaving the original unchanged. Members keep the order they
+// have in the original, and keys it doesn't have are ignored.
+//
+// Returns ErrType if the value is not an object.
+func (v *Value) Omit(keys ...string) (*Value, error) {
+	return v.filter(keys, false)
+}
+
+// Copies the members of an object whose keys are in the list, or not in it
+// if keep is false.
+func (v *Value) filter(keys []string, keep bool) (*Value, error) {
+	if v.jsonType != Object {
+		return &Value{}, v.typeError(Object)
+	}
+	listed := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		listed[k] = true
+	}
+	result := NewObject()
+	for _, p := range v.objectValue {
+		if listed[p.key] == keep {
+			result.objectValue = append(result.objectValue, pair{key: p.key, val: p.val.Clone()})
+		}
+	}
+	return result, nil
+}
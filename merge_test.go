@@ -0,0 +1,111 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	for _, test := range []struct {
+		base     string
+		override string
+		deep     bool
+		expected string
+	}{
+		{`{}`, `{}`, false, `{}`},
+		{`{"a": 1, "b": 2}`, `{"b": 3, "c": 4}`, false, `{"a": 1, "b": 3, "c": 4}`},
+		{`{"a": {"x": 1, "y": 2}}`, `{"a": {"y": 3}}`, false, `{"a": {"y": 3}}`},
+		{`{"a": {"x": 1, "y": 2}}`, `{"a": {"y": 3}}`, true, `{"a": {"x": 1, "y": 3}}`},
+		{`{"a": {"b": {"c": 1, "d": 2}}}`, `{"a": {"b": {"d": 3}}}`, true, `{"a": {"b": {"c": 1, "d": 3}}}`},
+		{`{"a": [1, 2]}`, `{"a": [3]}`, true, `{"a": [3]}`},
+		{`{"a": {"x": 1}}`, `{"a": 5}`, true, `{"a": 5}`},
+		{`{"a": 5}`, `{"a": {"x": 1}}`, true, `{"a": {"x": 1}}`},
+		{`{"a": 1}`, `{"a": null}`, true, `{"a": null}`},
+		{`{"a": 1, "a": 2}`, `{"a": 3}`, false, `{"a": 3}`},
+	} {
+		t.Run(test.base+" "+test.override, func(t *testing.T) {
+			base := MustParse(test.base)
+			override := MustParse(test.override)
+			baseString, overrideString := base.String(), override.String()
+			actual, err := Merge(base, override, test.deep)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if actual.String() != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if base.String() != baseString || override.String() != overrideString {
+				t.Errorf("expected %v %v got %v %v", baseString, overrideString, base, override)
+			}
+		})
+	}
+
+	obj := MustParse(`{"a": {"b": 1}}`)
+	merged, _ := Merge(obj, MustParse(`{}`), true)
+	merged.Key("a").Set("b", NewInt(2))
+	if n := obj.Get("a", "b").IntOr(0); n != 1 {
+		t.Errorf("expected %v got %v", 1, n)
+	}
+
+	for _, test := range []struct {
+		base     *Value
+		override *Value
+	}{
+		{NewArray(), NewObject()},
+		{NewObject(), NewNull()},
+	} {
+		if _, err := Merge(test.base, test.override, true); !errors.Is(err, ErrType) {
+			t.Errorf("expected %v got %v", ErrType, err)
+		}
+	}
+}
+
+func TestPickOmit(t *testing.T) {
+	input := `{"id": 1, "name": "a", "secret": {"k": "v"}, "id": 2, "tags": []}`
+	for _, test := range []struct {
+		keys []string
+		pick string
+		omit string
+	}{
+		{nil, `{}`, input},
+		{[]string{"name"}, `{"name": "a"}`, `{"id": 1, "secret": {"k": "v"}, "id": 2, "tags": []}`},
+		{[]string{"tags", "id", "missing"}, `{"id": 1, "id": 2, "tags": []}`, `{"name": "a", "secret": {"k": "v"}}`},
+		{[]string{"id", "name", "secret", "tags"}, input, `{}`},
+	} {
+		t.Run(strings.Join(test.keys, ","), func(t *testing.T) {
+			val := MustParse(input)
+			picked, err := val.Pick(test.keys...)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.pick); picked.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, picked)
+			}
+			omitted, err := val.Omit(test.keys...)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.omit); omitted.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, omitted)
+			}
+			if val.String() != MustParse(input).String() {
+				t.Errorf("expected %v got %v", input, val)
+			}
+		})
+	}
+
+	val := MustParse(`{"a": {"b": 1}}`)
+	picked, _ := val.Pick("a")
+	picked.Key("a").Set("b", NewInt(2))
+	if n := val.Get("a", "b").IntOr(0); n != 1 {
+		t.Errorf("expected %v got %v", 1, n)
+	}
+
+	if _, err := NewArray().Pick("a"); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+	if _, err := NewString("a").Omit("a"); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
@@ -2,12 +2,18 @@ package json
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 /*
@@ -21,10 +27,12 @@ It has been modified and expanded in the following ways:
 */
 
 const (
-	// Can only handle this many nested arrays and object.
+	// By default, can only handle this many nested arrays and object.
 	// If you data is deeper than this, you have bigger problems
 	// than the parser failing.
 	depth = 1024
+	// The byte order mark some tools put at the start of UTF-8 files.
+	byteOrderMark = '\uFEFF'
 )
 
 // The different input categories that provide the "columns" of the state transition table.
@@ -98,6 +106,8 @@ const (
 	e1              // e
 	e2              // ex
 	e3              // exp
+	pl              // plus
+	pt              // leading point
 	t1              // tr
 	t2              // tru
 	t3              // true
@@ -180,32 +190,46 @@ var asciiClasses = [129]charClass{
 	charEof__,
 }
 
+// Maps a rune to its character class. Negative runes are the end of input.
+func classify(r rune) charClass {
+	switch {
+	case r < 0:
+		return charEof__
+	case r >= utf8.RuneSelf:
+		return charEtc__
+	default:
+		return asciiClasses[r]
+	}
+}
+
 // Maps a state + input to a new state. Some states (-1 and lower) are actions with special property rules
 var stateTransitionTable = [numStates][numClasses]state{
 	/*  	                white                                                        1-9                                                ABCDF    etc
 	.               sp  \n  |   {   }   [   ]   :   ,   "   \   /   *   +   -   .   0   |   a   b   c   d   e   f   l   n   r   s   t   u   |   E   |  eof */
-	/* start  sr*/ {sr, sr, sr, so, __, sa, __, __, __, st, __, sc, __, __, mi, __, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
+	/* start  sr*/ {sr, sr, sr, so, __, sa, __, __, __, st, __, sc, __, pl, mi, pt, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
 	/* ok     ok*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, ok},
 	/* object ob*/ {ob, ob, ob, __, ee, __, __, __, __, st, __, sc, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* key    ke*/ {ke, ke, ke, __, ee, __, __, __, __, st, __, sc, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* colon  co*/ {co, co, co, __, __, __, __, ek, __, __, __, sc, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
-	/* comma  tc*/ {tc, tc, tc, so, __, sa, aa, __, __, st, __, sc, __, __, mi, __, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
-	/* value  va*/ {va, va, va, so, __, sa, __, __, __, st, __, sc, __, __, mi, __, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
-	/* array  ar*/ {ar, ar, ar, so, __, sa, aa, __, __, st, __, sc, __, __, mi, __, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
+	/* comma  tc*/ {tc, tc, tc, so, __, sa, aa, __, __, st, __, sc, __, pl, mi, pt, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
+	/* value  va*/ {va, va, va, so, __, sa, __, __, __, st, __, sc, __, pl, mi, pt, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
+	/* array  ar*/ {ar, ar, ar, so, __, sa, aa, __, __, st, __, sc, __, pl, mi, pt, ze, in, __, __, __, __, __, f1, __, n1, __, __, t1, __, __, __, __, __},
 	/* string st*/ {st, __, __, st, st, st, st, st, st, es, ec, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, st, __},
 	/* escape ec*/ {__, __, __, __, __, __, __, __, __, st, st, st, __, __, __, __, __, __, __, st, __, __, __, st, __, st, st, __, st, u1, __, __, __, __},
 	/* u1     u1*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, u2, u2, u2, u2, u2, u2, u2, u2, __, __, __, __, __, __, u2, u2, __, __},
 	/* u2     u2*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, u3, u3, u3, u3, u3, u3, u3, u3, __, __, __, __, __, __, u3, u3, __, __},
 	/* u3     u3*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, u4, u4, u4, u4, u4, u4, u4, u4, __, __, __, __, __, __, u4, u4, __, __},
 	/* u4     u4*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, st, st, st, st, st, st, st, st, __, __, __, __, __, __, st, st, __, __},
-	/* minus  mi*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, ze, in, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
+	/* minus  mi*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, pt, ze, in, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* zero   ze*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, fr, __, __, __, __, __, __, e1, __, __, __, __, __, __, __, __, e1, __, ok},
 	/* int    in*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, fr, in, in, __, __, __, __, e1, __, __, __, __, __, __, __, __, e1, __, ok},
-	/* frac   fr*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, fs, fs, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
+	/* frac   fr*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, __, fs, fs, __, __, __, __, e1, __, __, __, __, __, __, __, __, e1, __, ok},
 	/* fracs  fs*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, __, fs, fs, __, __, __, __, e1, __, __, __, __, __, __, __, __, e1, __, ok},
 	/* e      e1*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, e2, e2, __, e3, e3, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* ex     e2*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, e3, e3, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* exp    e3*/ {ok, ok, ok, __, eo, __, ea, __, ep, __, __, sc, __, __, __, __, e3, e3, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, ok},
+	/* plus   pl*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, pt, ze, in, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
+	/* point  pt*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, fs, fs, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __},
 	/* tr     t1*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, t2, __, __, __, __, __, __, __},
 	/* tru    t2*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, t3, __, __, __, __},
 	/* true   t3*/ {__, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, __, ok, __, __, __, __, __, __, __, __, __, __, __},
@@ -229,16 +253,128 @@ type parser struct {
 	state      state
 	modeTop    int
 	valueTop   int
-	modeStack  [depth]mode
-	valueStack [depth * 3]*Value
-	buffer     string
+	modeStack  []mode
+	valueStack []*Value
+	buffer     []byte
 	pos        int
+	line       int
+	column     int
+	options    ParseOptions
+	tokenize   bool
+	validate   bool
+	comment    []byte
+	comments   []string
+	reconsume  bool
+	tokens     []Token
+	tokenStart span
+	stats      ParseStats
+	recycling  bool
+	interned   map[string]string
+	// the state a comment was started in, to go back to after it
+	afterComment state
+	// how many elements each array being parsed has had, for the reviver
+	indexes []int
+	// recycled values and members to build the tree out of
+	free        []*Value
+	freeArrays  [][]*Value
+	freeObjects [][]pair
+}
+
+// Counts of what the parser saw in its input, such as for metrics about
+// the documents being parsed.
+type ParseStats struct {
+	// The number of bytes read.
+	Bytes int
+	// The number of objects.
+	Objects int
+	// The number of arrays.
+	Arrays int
+	// The number of strings, not counting object keys.
+	Strings int
+	// The number of numbers, both integers and those with a fraction or exponent.
+	Numbers int
+	// How deeply arrays and objects were nested, with 0 meaning that
+	// there were none, in the same units as Value.MaxDepth.
+	MaxDepth int
+}
+
+// Notes that an array or object was started at the current depth.
+func (p *parser) countDepth() {
+	if p.modeTop > p.stats.MaxDepth {
+		p.stats.MaxDepth = p.modeTop
+	}
+}
+
+// Describes where in the input parsing failed. Matches ErrParse with errors.Is.
+type ParseError struct {
+	// The line of the offending character, starting from 1.
+	Line int
+	// The column of the offending character in runes, starting from 1.
+	Column int
+	// The byte offset of the offending character, starting from 0.
+	Offset int
+	msg    string
+	err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: %s at line %d, column %d (byte %d)", ErrParse, e.msg, e.Line, e.Column, e.Offset)
+}
+
+// Allows errors.Is to match ErrParse, as well as the more specific error
+// such as ErrUnmatchedBrace if there is one.
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// The error a ParseError wraps for a repeated key, which matches ErrParse
+// like the other parse errors and ErrDuplicateKey like AsObjectWith.
+type duplicateKeyError struct{}
+
+func (duplicateKeyError) Error() string {
+	return ErrDuplicateKey.Error()
+}
+
+// Allows errors.Is to match both ErrParse and ErrDuplicateKey.
+func (duplicateKeyError) Is(target error) bool {
+	return target == ErrParse || target == ErrDuplicateKey
+}
+
+// Creates an error pointing at the parser's current position. The error
+// matches err, which should be ErrParse or one of the errors wrapping it.
+func (p *parser) errorf(err error, format string, args ...any) error {
+	return &ParseError{
+		Line:   p.line,
+		Column: p.column,
+		Offset: p.pos,
+		msg:    fmt.Sprintf(format, args...),
+		err:    err,
+	}
+}
+
+// Moves the position forward past a character that was n bytes wide.
+func (p *parser) advance(r rune, n int) {
+	if n == 0 {
+		// EOF takes up no space
+		return
+	}
+	p.pos += n
+	if r == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
 }
 
 // Puts a value onto the value stack. Correct parsing should end
 // with a single value left on the stack.
 func (p *parser) pushValue(v *Value) {
 	p.valueTop++
+	if p.valueTop == len(p.valueStack) {
+		p.valueStack = append(p.valueStack, v)
+		return
+	}
 	p.valueStack[p.valueTop] = v
 }
 
@@ -250,11 +386,17 @@ func (p *parser) popValue() *Value {
 }
 
 // Push a mode to the mode stack. Correct parsing should end
-// with modeDone being the only thing left on the stack.
+// with modeDone being the only thing left on the stack. Since modeDone is
+// at the bottom, the nesting depth of an array or object is its index.
 func (p *parser) pushMode(m mode) error {
 	p.modeTop++
-	if p.modeTop >= depth {
-		return fmt.Errorf("%w: nested JSON max depth exceeded at byte %d", ErrParse, p.pos)
+	if (m == modeArray || m == modeKey) && p.modeTop > p.options.MaxDepth {
+		p.isRunning = false
+		return p.errorf(ErrDepthExceeded, "nested JSON max depth exceeded")
+	}
+	if p.modeTop == len(p.modeStack) {
+		p.modeStack = append(p.modeStack, m)
+		return nil
 	}
 	p.modeStack[p.modeTop] = m
 	return nil
@@ -263,7 +405,8 @@ func (p *parser) pushMode(m mode) error {
 // Pulls a mode from the stack.
 func (p *parser) popMode(m mode) error {
 	if p.modeStack[p.modeTop] != m {
-		return fmt.Errorf("%w: unmatched closing brace at %d", ErrParse, p.pos)
+		p.isRunning = false
+		return p.errorf(ErrUnmatchedBrace, "unmatched closing brace")
 	}
 	p.modeTop--
 	return nil
@@ -276,47 +419,425 @@ func (p *parser) peekMode() mode {
 
 // An impossible input under correct JSON grammar has been reached. Can happen for several reasons.
 func (p *parser) reject() error {
+	if p.isEOF {
+		return p.incomplete()
+	}
 	p.isRunning = false
-	return fmt.Errorf("%w: invalid character reached at byte %d", ErrParse, p.pos)
+	return p.errorf(ErrParse, "invalid character")
+}
+
+// The input ended before the value did. Says which kind of container was
+// left open, if any.
+func (p *parser) incomplete() error {
+	p.isRunning = false
+	switch p.peekMode() {
+	case modeArray:
+		return p.errorf(ErrUnexpectedEOF, "unexpected end of input while parsing array")
+	case modeKey, modeObject:
+		return p.errorf(ErrUnexpectedEOF, "unexpected end of input while parsing object")
+	}
+	return p.errorf(ErrUnexpectedEOF, "unexpected end of input")
+}
+
+// Checks a transition against the extensions to the grammar that
+// may be turned off in the options.
+func (p *parser) isAllowed(nextState state) bool {
+	switch {
+	case p.state == fr && nextState != fs && !p.options.AllowLenientNumbers:
+		// A number ending with ., checked first since a comment can end it
+		return false
+	case nextState == sc:
+		return p.options.AllowComments
+	case p.state == tc && nextState == aa, p.state == ke && nextState == ee:
+		// An array or object closing right after a comma
+		return p.options.AllowTrailingCommas
+	case nextState == pl, nextState == pt:
+		// A number starting with + or .
+		return p.options.AllowLenientNumbers
+	}
+	return true
 }
 
 // We're at a point where,due to a closing brace, we are done with a literal value,
 // but it hasn't been added to the stack yet. So we clip it here and push the value.
 // This only happens for numbers (and integers), as the other values have explicit
-// terminating characters.
-func (p *parser) terminateLiterals(r rune) {
+// terminating characters. Fails if the number can't be represented.
+func (p *parser) terminateLiterals(r rune) error {
+	var v *Value
+	var err error
+	if p.options.AllowLenientNumbers {
+		p.buffer = normalizeNumber(p.buffer)
+	}
 	switch p.state {
 	case ze, in:
 		// Accept an integer value
-		val, _ := strconv.ParseInt(p.buffer, 10, 64)
-		p.pushValue(&Value{jsonType: Integer, integerValue: val})
-		p.buffer = ""
-	case fs, e3:
+		v, err = p.number(true)
+	case fr, fs, e3:
 		// Accept an Number value
-		val, _ := strconv.ParseFloat(p.buffer, 64)
-		p.pushValue(&Value{jsonType: Number, numberValue: val})
-		p.buffer = ""
+		v, err = p.number(false)
+	default:
+		return nil
+	}
+	p.buffer = p.buffer[:0]
+	if err != nil {
+		p.isRunning = false
+		return p.errorf(ErrParse, "%v", err)
+	}
+	p.stats.Numbers++
+	if !p.validate {
+		p.acceptValue(v, p.pos)
+	}
+	return nil
+}
+
+// Converts the number literal in the buffer to a value of the type the
+// options' NumberMode calls for, where integer is whether it was written
+// without a fraction or exponent.
+func (p *parser) number(integer bool) (*Value, error) {
+	if p.validate && !p.options.ExactDecimals {
+		return nil, p.checkNumber(integer)
+	}
+	s := string(p.buffer)
+	var v Value
+	var err error
+	switch {
+	case p.options.NumberMode == RawNumbers:
+		v = Value{jsonType: String, stringValue: s}
+	case integer && p.options.NumberMode != AlwaysFloat:
+		v, err = parseInteger(s)
+	default:
+		v, err = parseNumber(s)
+		if err == nil && p.options.NumberMode == PreferInteger {
+			preferInteger(&v)
+		}
+		if err == nil && v.jsonType == Number && p.options.ExactDecimals {
+			v.decimalValue, err = parseDecimal(s)
+		}
+	}
+	if err != nil || p.validate {
+		return nil, err
+	}
+	return p.newValue(v), nil
+}
+
+// Checks that the number literal in the buffer can be converted the way
+// number would, without building a value, for when the parser is only
+// validating. Any integer can be converted, if need be to a big.Int.
+func (p *parser) checkNumber(integer bool) error {
+	if p.options.NumberMode == RawNumbers || (integer && p.options.NumberMode != AlwaysFloat) {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(string(p.buffer), 64); err != nil {
+		return fmt.Errorf("number %s out of range", p.buffer)
+	}
+	return nil
+}
+
+// Converts the bytes of a string to a string. If strings are interned, equal
+// strings share the same memory.
+func (p *parser) intern(b []byte) string {
+	if !p.options.InternStrings {
+		return string(b)
+	}
+	if s, ok := p.interned[string(b)]; ok {
+		return s
+	}
+	if p.interned == nil {
+		p.interned = map[string]string{}
+	}
+	s := string(b)
+	p.interned[s] = s
+	return s
+}
+
+// Gets a new value to add to the tree, reusing the memory of recycled values
+// and their members if there are any left.
+func (p *parser) newValue(v Value) *Value {
+	if v.arrayValue != nil && len(p.freeArrays) > 0 {
+		v.arrayValue = p.freeArrays[len(p.freeArrays)-1]
+		p.freeArrays = p.freeArrays[:len(p.freeArrays)-1]
+	}
+	if v.objectValue != nil && len(p.freeObjects) > 0 {
+		v.objectValue = p.freeObjects[len(p.freeObjects)-1]
+		p.freeObjects = p.freeObjects[:len(p.freeObjects)-1]
+	}
+	var node *Value
+	if n := len(p.free); n > 0 {
+		node = p.free[n-1]
+		p.free = p.free[:n-1]
+	} else {
+		node = new(Value)
+	}
+	*node = v
+	return node
+}
+
+// Adds the values under v, though not v itself, to the values the parser
+// builds new trees out of.
+func (p *parser) recycle(v *Value) {
+	for i, val := range v.arrayValue {
+		p.recycleValue(val)
+		v.arrayValue[i] = nil
+	}
+	for i, m := range v.objectValue {
+		p.recycleValue(m.val)
+		v.objectValue[i] = pair{}
+	}
+}
+
+// Adds a value and the values under it to the recycled values, clearing them
+// so they don't hold on to anything. The memory arrays and objects use for
+// their members is kept too. A value that is in the tree more than once is
+// only added the first time.
+func (p *parser) recycleValue(v *Value) {
+	if v.jsonType == typeUnknown {
+		return
+	}
+	v.jsonType = typeUnknown
+	p.recycle(v)
+	if cap(v.arrayValue) > 0 {
+		p.freeArrays = append(p.freeArrays, v.arrayValue[:0])
+	}
+	if cap(v.objectValue) > 0 {
+		p.freeObjects = append(p.freeObjects, v.objectValue[:0])
+	}
+	*v = Value{jsonType: typeUnknown}
+	p.free = append(p.free, v)
+}
+
+// Rewrites a number accepted by AllowLenientNumbers in standard form,
+// so that its raw text is still valid JSON. A leading + is dropped,
+// and a 0 is added on whichever side of the decimal point has no digits.
+func normalizeNumber(b []byte) []byte {
+	if len(b) > 0 && b[0] == '+' {
+		b = append(b[:0], b[1:]...)
+	}
+	i := bytes.IndexByte(b, '.')
+	if i < 0 {
+		return b
+	}
+	if i == len(b)-1 || b[i+1] == 'e' || b[i+1] == 'E' {
+		b = append(b[:i+1], append([]byte{'0'}, b[i+1:]...)...)
+	}
+	if i == 0 || b[i-1] == '-' {
+		b = append(b[:i], append([]byte{'0'}, b[i:]...)...)
+	}
+	return b
+}
+
+// Converts an integer literal to a value. Integers that don't fit in an int64
+// are kept as a big.Int rather than being truncated.
+func parseInteger(s string) (Value, error) {
+	if val, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Value{jsonType: Integer, integerValue: val, rawNumber: s}, nil
+	}
+	val, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Value{}, fmt.Errorf("invalid integer %s", s)
+	}
+	return Value{jsonType: Integer, bigValue: val, rawNumber: s}, nil
+}
+
+// Converts a number literal with a fraction or exponent to a value.
+// Fails if the number is too large to be a float64.
+func parseNumber(s string) (Value, error) {
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Value{}, fmt.Errorf("number %s out of range", s)
+	}
+	return Value{jsonType: Number, numberValue: val, rawNumber: s}, nil
+}
+
+// Turns a number with a fraction or exponent into an integer if it is a
+// whole number that fits in an int64, such as 1e2 or 5.0.
+func preferInteger(v *Value) {
+	f := v.numberValue
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return
+	}
+	// The float64 may have been rounded, so check the literal itself
+	r, err := parseDecimal(v.rawNumber)
+	if err != nil || !r.IsInt() || !r.Num().IsInt64() {
+		return
+	}
+	v.jsonType, v.integerValue, v.numberValue = Integer, r.Num().Int64(), 0
+}
+
+// Converts a quoted string literal to the bytes of the string it represents.
+// The PDA has already checked that every escape sequence is well-formed.
+// Since unescaping never makes a string longer, it's done in place, and the
+// result shares memory with b. Surrogate pairs are combined into one rune.
+// Fails if a surrogate is not part of a pair.
+func unquote(b []byte) ([]byte, error) {
+	src := b[1 : len(b)-1]
+	if bytes.IndexByte(src, '\\') < 0 {
+		return src, nil
+	}
+
+	s := b[:0]
+	for i := 0; i < len(src); i++ {
+		if src[i] != '\\' {
+			s = append(s, src[i])
+			continue
+		}
+		i++
+		switch src[i] {
+		case '"', '\\', '/':
+			s = append(s, src[i])
+		case 'b':
+			s = append(s, '\b')
+		case 'f':
+			s = append(s, '\f')
+		case 'n':
+			s = append(s, '\n')
+		case 'r':
+			s = append(s, '\r')
+		case 't':
+			s = append(s, '\t')
+		case 'u':
+			r := hexRune(src[i+1 : i+5])
+			i += 4
+			if utf16.IsSurrogate(r) {
+				// The second half of the pair must follow right away
+				if i+6 >= len(src) || src[i+1] != '\\' || src[i+2] != 'u' {
+					return nil, fmt.Errorf("unpaired surrogate \\u%04x", r)
+				}
+				r = utf16.DecodeRune(r, hexRune(src[i+3:i+7]))
+				if r == unicode.ReplacementChar {
+					return nil, fmt.Errorf("invalid surrogate pair %s", src[i-5:i+7])
+				}
+				i += 6
+			}
+			s = utf8.AppendRune(s, r)
+		}
+	}
+	return s, nil
+}
+
+// Reads four hex digits as a rune.
+func hexRune(b []byte) rune {
+	var r rune
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		}
+		r = r<<4 | rune(c)
+	}
+	return r
+}
+
+// Puts a finished literal value that ended just before the given offset
+// onto the value stack.
+func (p *parser) acceptValue(v *Value, end int) {
+	p.tokenStart.end = end
+	v.source = p.tokenStart
+	p.attachComments(v)
+	p.pushValue(v)
+	p.emit(tokenKinds[v.jsonType], v, p.tokenStart.start)
+}
+
+// Marks the array or object on top of the value stack as ending with the
+// current character.
+func (p *parser) endContainer() {
+	if !p.validate {
+		p.valueStack[p.valueTop].source.end = p.pos + 1
+	}
+}
+
+// Marks the array on top of the value stack as ending with the current
+// character, and forgets how many elements it had for the reviver.
+func (p *parser) endArray() {
+	p.endContainer()
+	if p.options.Reviver != nil && !p.validate {
+		p.indexes = p.indexes[:len(p.indexes)-1]
+	}
+}
+
+// Runs the reviver, if there is one, on a value that was just completed,
+// getting the value to use in its place.
+func (p *parser) revive(key string, v *Value) (*Value, error) {
+	if p.options.Reviver == nil || p.tokenize {
+		return v, nil
+	}
+	v, err := p.options.Reviver(key, v)
+	if err != nil {
+		p.isRunning = false
+		return nil, err
+	}
+	return v, nil
+}
+
+// Gives a new value the comments read since the last value was started.
+func (p *parser) attachComments(v *Value) {
+	if len(p.comments) > 0 {
+		v.comments = p.comments
+		p.comments = nil
 	}
 }
 
 // We're in array mode, and found a child object, so add it to the array
 // as we go on. This way at most one child object is on the stack for an
 // array at any time, and the rest are held in the array itself.
-func (p *parser) growArray() {
+// Fails if the reviver does.
+func (p *parser) growArray() error {
+	if p.validate {
+		return nil
+	}
 	val := p.popValue()
 	arr := p.popValue()
-	arr.arrayValue = append(arr.arrayValue, val)
+	if p.options.Reviver != nil {
+		// The index in the source, counting elements the reviver dropped
+		i := len(p.indexes) - 1
+		var err error
+		if val, err = p.revive(strconv.Itoa(p.indexes[i]), val); err != nil {
+			return err
+		}
+		p.indexes[i]++
+	}
+	if !p.tokenize && val != nil {
+		arr.arrayValue = append(arr.arrayValue, val)
+	}
 	p.pushValue(arr)
+	return nil
 }
 
 // We're in object mode, and found a child k/v pair, so add it to the object
 // as we go on. This way at most one child pair is on the stack for an
 // object at any time, and the rest are held in the object itself.
-func (p *parser) growObject() {
-	v, k := p.popValue(), p.popValue().stringValue
+// Fails if duplicate keys are rejected and the key is already present, or
+// if the reviver fails.
+func (p *parser) growObject() error {
+	if p.validate {
+		return nil
+	}
+	v, key := p.popValue(), p.popValue()
+	k, at := key.stringValue, key.source
+	if p.recycling && !p.tokenize {
+		// Nothing else refers to the key's value
+		p.free = append(p.free, key)
+	}
 	obj := p.popValue()
-	obj.objectValue = append(obj.objectValue, pair{key: k, val: v})
+	if p.options.RejectDuplicateKeys && obj.Has(k) {
+		p.isRunning = false
+		err := p.errorf(duplicateKeyError{}, "duplicate key %q", k).(*ParseError)
+		// Point at the repeated key rather than the end of its member
+		err.Line, err.Column, err.Offset = at.line, at.column, at.start
+		return err
+	}
+	v, err := p.revive(k, v)
+	if err != nil {
+		return err
+	}
+	if !p.tokenize && v != nil {
+		obj.objectValue = append(obj.objectValue, pair{key: k, val: v})
+	}
 	p.pushValue(obj)
+	return nil
 }
 
 // Run one step of the PDA. Also handles the logic of the action states.
@@ -326,10 +847,17 @@ func (p *parser) consumeCharacter(r rune) error {
 
 	if p.isEOF {
 		nextClass = charEof__
-	} else if r >= 128 {
-		nextClass = charEtc__
 	} else {
-		nextClass = asciiClasses[r]
+		nextClass = classify(r)
+	}
+
+	if p.state == st && !p.isEOF && r < 0x20 {
+		// RFC 8259 only allows control characters in strings as escapes
+		if !p.options.AllowControlCharacters {
+			p.isRunning = false
+			return p.errorf(ErrParse, "unescaped control character %U in string", r)
+		}
+		nextClass = charEtc__
 	}
 
 	if nextClass == _________ {
@@ -337,33 +865,56 @@ func (p *parser) consumeCharacter(r rune) error {
 	}
 
 	nextState = stateTransitionTable[p.state][nextClass]
+	if !p.isAllowed(nextState) {
+		return p.reject()
+	}
+	switch p.state {
+	case sr, ob, ke, tc, va, ar:
+		// Any literal starts here
+		p.tokenStart = span{start: p.pos, line: p.line, column: p.column}
+	}
 	// Handle regular state transitions
 	if nextState >= 0 {
 		switch nextState {
-		case t1, t2, t3, f1, f2, f3, f4, mi, ze, in, fr, fs, e1, e2, e3, st, ec, u1, u2, u3, u4:
-			p.buffer = p.buffer + string(r)
+		case st, ec, u1, u2, u3, u4:
+			// Strings are kept even when validating so their escapes can be checked
+			p.buffer = utf8.AppendRune(p.buffer, r)
+		case mi, ze, in, fr, fs, e1, e2, e3, pl, pt:
+			// So are numbers, so their range can be checked
+			p.buffer = utf8.AppendRune(p.buffer, r)
+		case t1, t2, t3, f1, f2, f3, f4:
+			if !p.validate {
+				p.buffer = utf8.AppendRune(p.buffer, r)
+			}
+		case c1, c2, c3, c4:
+			if p.options.PreserveComments {
+				p.comment = utf8.AppendRune(p.comment, r)
+			}
 		case ok:
 			switch p.state {
 			case n3:
 				// Accept a null value
-				p.pushValue(&Value{jsonType: Null})
-				p.buffer = ""
+				if !p.validate {
+					p.acceptValue(p.newValue(Value{jsonType: Null}), p.pos+1)
+				}
+				p.buffer = p.buffer[:0]
 			case f4, t3:
 				// Accept a bool value
-				p.buffer = p.buffer + string(r)
-				val, _ := strconv.ParseBool(p.buffer)
-				p.pushValue(&Value{jsonType: Boolean, booleanValue: val})
-				p.buffer = ""
-			case ze, in:
-				// Accept an integer value
-				val, _ := strconv.ParseInt(p.buffer, 10, 64)
-				p.pushValue(&Value{jsonType: Integer, integerValue: val})
-				p.buffer = ""
-			case fs, e3:
-				// Accept an Number value
-				val, _ := strconv.ParseFloat(p.buffer, 64)
-				p.pushValue(&Value{jsonType: Number, numberValue: val})
-				p.buffer = ""
+				if p.validate {
+					break
+				}
+				p.buffer = utf8.AppendRune(p.buffer, r)
+				val, err := strconv.ParseBool(string(p.buffer))
+				if err != nil {
+					p.isRunning = false
+					return p.errorf(ErrParse, "invalid boolean %s", p.buffer)
+				}
+				p.acceptValue(p.newValue(Value{jsonType: Boolean, booleanValue: val}), p.pos+1)
+				p.buffer = p.buffer[:0]
+			case ze, in, fr, fs, e3:
+				if err := p.terminateLiterals(r); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -375,136 +926,563 @@ func (p *parser) consumeCharacter(r rune) error {
 	switch nextState {
 	case ee:
 		// End Empty Object
-		p.popMode(modeKey)
+		if err := p.popMode(modeKey); err != nil {
+			return err
+		}
+		p.endContainer()
+		p.comments = nil
+		p.emit(TokenObjectEnd, nil, p.pos)
 		p.state = ok
 		//
 	case eo:
 		// End non-empty object
 
 		if err := p.popMode(modeObject); err != nil {
-			return p.reject()
+			return err
+		}
+		if err := p.terminateLiterals(r); err != nil {
+			return err
+		}
+		if err := p.growObject(); err != nil {
+			return err
 		}
-		p.terminateLiterals(r)
-		p.growObject()
+		p.endContainer()
+		p.comments = nil
+		p.emit(TokenObjectEnd, nil, p.pos)
 		p.state = ok
 	case aa:
 		// End empty array
-		p.popMode(modeArray)
+		if err := p.popMode(modeArray); err != nil {
+			return err
+		}
+		p.endArray()
+		p.comments = nil
+		p.emit(TokenArrayEnd, nil, p.pos)
 		p.state = ok
 	case ea:
 		// End array
 
 		if err := p.popMode(modeArray); err != nil {
-			return p.reject()
+			return err
+		}
+		if err := p.terminateLiterals(r); err != nil {
+			return err
+		}
+		if err := p.growArray(); err != nil {
+			return err
 		}
-		p.terminateLiterals(r)
-		p.growArray()
+		p.endArray()
+		p.comments = nil
+		p.emit(TokenArrayEnd, nil, p.pos)
 		p.state = ok
 	case so:
 		// Start object
 		if err := p.pushMode(modeKey); err != nil {
-			return p.reject()
+			return err
 		}
 
-		p.pushValue(&Value{jsonType: Object, objectValue: []pair{}})
+		p.stats.Objects++
+		p.countDepth()
+		if !p.validate {
+			obj := p.newValue(Value{jsonType: Object, objectValue: []pair{}})
+			obj.source = span{start: p.pos, line: p.line, column: p.column}
+			p.attachComments(obj)
+			p.pushValue(obj)
+		}
+		p.emit(TokenObjectStart, nil, p.pos)
 		p.state = ob
 	case sa:
 		// Start array
 		if err := p.pushMode(modeArray); err != nil {
-			return p.reject()
+			return err
+		}
+		p.stats.Arrays++
+		p.countDepth()
+		if !p.validate {
+			arr := p.newValue(Value{jsonType: Array, arrayValue: []*Value{}})
+			arr.source = span{start: p.pos, line: p.line, column: p.column}
+			p.attachComments(arr)
+			p.pushValue(arr)
+			if p.options.Reviver != nil {
+				p.indexes = append(p.indexes, 0)
+			}
 		}
-		p.pushValue(&Value{jsonType: Array, arrayValue: []*Value{}})
+		p.emit(TokenArrayStart, nil, p.pos)
 		p.state = ar
 	case es:
 		// End String
 		// Accept the built string value
-		p.buffer = p.buffer + string(r)
-		val, _ := strconv.Unquote(strings.Replace(p.buffer, `\/`, `/`, -1))
-		p.pushValue(&Value{jsonType: String, stringValue: val})
-		p.buffer = ""
+		p.buffer = utf8.AppendRune(p.buffer, r)
+		str, err := unquote(p.buffer)
+		if err != nil {
+			p.isRunning = false
+			return p.errorf(ErrParse, "%v", err)
+		}
+		if p.peekMode() != modeKey {
+			p.stats.Strings++
+		}
+		if p.validate {
+			p.buffer = p.buffer[:0]
+			p.state = ok
+			if p.peekMode() == modeKey {
+				p.state = co
+			}
+			break
+		}
+		val := p.newValue(Value{jsonType: String, stringValue: p.intern(str)})
+		val.source = p.tokenStart
+		val.source.end = p.pos + 1
+		p.buffer = p.buffer[:0]
 		switch p.peekMode() {
 		case modeKey:
+			// Comments before a key belong to the member's value
+			p.pushValue(val)
+			p.emit(TokenKey, val, p.tokenStart.start)
 			p.state = co
 		default:
+			p.attachComments(val)
+			p.pushValue(val)
+			p.emit(TokenString, val, p.tokenStart.start)
 			p.state = ok
 		}
 	case ep:
 		// End an array element or object pair
 		// See comma
-		p.terminateLiterals(r)
+		if err := p.terminateLiterals(r); err != nil {
+			return err
+		}
 
 		switch p.peekMode() {
 		case modeArray:
-			p.growArray()
+			if err := p.growArray(); err != nil {
+				return err
+			}
 			p.state = tc
 		case modeObject:
-			p.growObject()
-			p.popMode(modeObject)
-			p.pushMode(modeKey)
+			if err := p.growObject(); err != nil {
+				return err
+			}
+			if err := p.popMode(modeObject); err != nil {
+				return err
+			}
+			if err := p.pushMode(modeKey); err != nil {
+				return err
+			}
 			p.state = ke
 		default:
 			return p.reject()
 		}
 	case ek:
 		// See colon
-		p.popMode(modeKey)
-		p.pushMode(modeObject)
+		if err := p.popMode(modeKey); err != nil {
+			return err
+		}
+		if err := p.pushMode(modeObject); err != nil {
+			return err
+		}
 		p.state = va
 	case sc:
-		p.pushMode(mode(p.state))
+		// A comment ends a number just like whitespace does, so that
+		// the number doesn't get the comment that comes after it.
+		if err := p.terminateLiterals(r); err != nil {
+			return err
+		}
+		switch p.state {
+		case ze, in, fr, fs, e3:
+			p.state = ok
+		}
+		if p.options.PreserveComments {
+			p.comment = utf8.AppendRune(p.comment[:0], r)
+		}
+		// Comments don't nest, so the state to go back to is kept aside
+		// rather than on the mode stack, where it would count as depth
+		p.afterComment = p.state
 		p.state = c1
 	case ce:
-		p.state = state(p.peekMode())
-		p.popMode(mode(p.state))
+		if p.options.PreserveComments {
+			if p.state == c4 {
+				// Block comments end with the closing slash
+				p.comment = utf8.AppendRune(p.comment, r)
+			}
+			p.comments = append(p.comments, string(p.comment))
+		}
+		p.state = p.afterComment
 	case cc:
 		// We have an eof, so get back to the previous state
-		// before the comment and rerun the logic before stopping
-		p.state = state(p.peekMode())
-		p.popMode(mode(p.state))
-		p.consumeCharacter(r)
+		// before the comment and have step rerun the logic before stopping
+		p.state = p.afterComment
+		p.reconsume = true
 	default:
 		return p.reject()
 	}
 	return nil
 }
 
-// Parses a JSON value from a Reader. If it cannot read a valid value,
+// Settings that control which inputs the parser accepts.
+type ParseOptions struct {
+	// Accept line (// ...) and block (/* ... */) comments.
+	AllowComments bool
+	// Accept a comma after the last member of an array or object.
+	AllowTrailingCommas bool
+	// Keep comments, attaching each one to the value that follows it, where
+	// it can be read with Comments. Comments before an object key belong to
+	// the value of that member, and comments with no value after them, such
+	// as those before a closing brace or at the end of the input, are dropped.
+	// Only has an effect if AllowComments is also set.
+	PreserveComments bool
+	// Accept numbers with a leading + or a decimal point with no digits
+	// before or after it, such as +5, .5 and 5., which are read as if they
+	// were written 5, 0.5 and 5.0.
+	AllowLenientNumbers bool
+	// Accept control characters (U+0000 to U+001F), such as tabs and
+	// newlines, written as is inside strings rather than escaped.
+	AllowControlCharacters bool
+	// Keep the exact value of numbers with a fraction or exponent as well as
+	// their float64 value, so that AsDecimal can return them without any
+	// rounding. Numbers with an exponent beyond ±1000 are rejected.
+	ExactDecimals bool
+	// How numbers are typed. The zero value types them by how they're written.
+	NumberMode NumberMode
+	// Fail if an object contains the same key more than once, with an error
+	// that matches both ErrParse and ErrDuplicateKey and points at the key.
+	RejectDuplicateKeys bool
+	// Make equal strings and object keys share memory, rather than each
+	// having its own copy, which can save a lot of memory for documents that
+	// repeat the same keys and values many times, such as arrays of records.
+	// It takes a little more time and memory for the parser itself.
+	InternStrings bool
+	// How deeply arrays and objects may be nested, counted the same way as
+	// Value.MaxDepth, so 1 allows [1] but not [[1]]. Zero or less means the
+	// default of 1024.
+	MaxDepth int
+	// Called with each value as soon as it is parsed, along with its key in
+	// the object holding it, its index in the source array holding it, or ""
+	// for the whole document, like the reviver in JavaScript's JSON.parse.
+	// Values are complete when it's called, so an array or object is seen
+	// after the values in it. The value it returns is used in place of the
+	// parsed one, and returning nil leaves the member out of its array or
+	// object, or makes the whole document null. An error stops parsing and
+	// is returned as is.
+	Reviver func(key string, v *Value) (*Value, error)
+	// How many bytes of input may be read, so that a huge input can't use up
+	// all the memory. Parsing stops with ErrTooLarge as soon as the limit is
+	// passed. Zero or less means there is no limit.
+	MaxBytes int
+}
+
+// How a parser decides the type of a number.
+type NumberMode int
+
+// Possible number modes
+const (
+	// Numbers with a fraction or exponent are Numbers, and the rest, however
+	// large, are Integers.
+	NumbersBySyntax NumberMode = iota
+	// Like NumbersBySyntax, except that numbers with a fraction or exponent
+	// that are whole and fit in an int64, such as 1e2 and 5.0, are Integers.
+	PreferInteger
+	// Every number is a float64 Number, even if it is written as an integer.
+	AlwaysFloat
+	// Numbers aren't converted at all. Each one is kept as a String holding
+	// its literal text, such as "1e2", for the caller to convert as it likes.
+	RawNumbers
+)
+
+// Gets the options used by Parse, which accept comments and trailing commas.
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		AllowComments:       true,
+		AllowTrailingCommas: true,
+		MaxDepth:            depth,
+	}
+}
+
+// Parses a JSON value from a Reader. A reader that implements
+// io.ByteReader or io.RuneReader is read from directly, and any other
+// reader is buffered. If it cannot read a valid value,
 // it returns a null value and a non-nil error.
 // Returns the parsed value and nil error otherwise.
 func Parse(r io.Reader) (*Value, error) {
-	pda := &parser{
-		isRunning:  true,
-		isEOF:      false,
-		state:      sr,
-		modeTop:    -1,
-		valueTop:   -1,
-		valueStack: [depth * 3]*Value{{}},
+	return ParseWithOptions(r, DefaultParseOptions())
+}
+
+// Parses a JSON value from a Reader, accepting only the extensions to
+// RFC 8259 enabled in the options. The zero value of ParseOptions
+// only accepts strictly valid JSON. If it cannot read a valid value,
+// it returns a null value and a non-nil error.
+// Returns the parsed value and nil error otherwise.
+func ParseWithOptions(r io.Reader, options ParseOptions) (*Value, error) {
+	return parse(context.Background(), r, options)
+}
+
+// Parses a JSON value from a Reader like Parse, but stops early with the
+// context's error if the context is cancelled or its deadline passes.
+// The context is checked before every read from r, so a slow reader stops
+// as soon as its current Read returns, but a Read call that blocks forever
+// will not be interrupted.
+func ParseContext(ctx context.Context, r io.Reader) (*Value, error) {
+	return parse(ctx, r, DefaultParseOptions())
+}
+
+// Parses a JSON value from a Reader like ParseWithOptions, but stops early
+// with the context's error like ParseContext.
+func ParseContextWithOptions(ctx context.Context, r io.Reader, options ParseOptions) (*Value, error) {
+	return parse(ctx, r, options)
+}
+
+// Parses a JSON value from a function that returns one character at a time,
+// for input that isn't UTF-8 and is decoded on the fly, such as by a
+// golang.org/x/text transformer. Each call returns the next rune and its
+// size in the source, or io.EOF once there are no more. The sizes are only
+// used for the offsets in errors and spans, so they may be in whatever units
+// suit the source, and any other error stops parsing and is returned as is.
+// If it cannot read a valid value, it returns a null value and a non-nil error.
+// Returns the parsed value and nil error otherwise.
+func ParseRunes(next func() (rune, int, error)) (*Value, error) {
+	return newParser(DefaultParseOptions()).parse(runeFunc(next))
+}
+
+// Lets a function that returns runes be used as an io.RuneReader.
+type runeFunc func() (rune, int, error)
+
+// Calls the function to get the next rune.
+func (f runeFunc) ReadRune() (rune, int, error) {
+	return f()
+}
+
+// Parses a JSON value from a Reader like ParseWithOptions, and also counts
+// what was in the input. The counts cover as much of the input as was read,
+// even if it cannot read a valid value, in which case it returns a null
+// value and a non-nil error.
+func ParseWithStats(r io.Reader, options ParseOptions) (*Value, ParseStats, error) {
+	p := newParser(options)
+	v, err := p.parse(newRuneReader(r))
+	p.stats.Bytes = p.pos
+	return v, p.stats, err
+}
+
+// Runs the parser over the whole input, checking the context as it goes.
+func parse(ctx context.Context, r io.Reader, options ParseOptions) (*Value, error) {
+	if ctx.Done() != nil {
+		r = &contextReader{ctx: ctx, r: r}
 	}
-	pda.pushMode(modeDone)
+	return newParser(options).parse(newRuneReader(r))
+}
 
-	b := bufio.NewReader(r)
+// Checks a context before every read from a reader, so that reading stops
+// once the context is done. It only implements io.Reader, so it is buffered
+// and only read from when the buffer runs out.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	// main loop
-	for pda.isRunning {
-		r, n, err := b.ReadRune()
+// Reads from the reader unless the context is done.
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// Gets a RuneReader for the input, buffering it only if it can't already
+// be read a rune or a byte at a time.
+func newRuneReader(r io.Reader) io.RuneReader {
+	if rr, ok := unbuffered(r); ok {
+		return rr
+	}
+	return bufio.NewReader(r)
+}
+
+// Gets a RuneReader that reads straight from the input without another
+// layer of buffering, if the input already supports reading runes or
+// bytes one at a time. A reader that can read bytes is decoded a byte at
+// a time unless it is a bufio.Reader, so that characters cut off by the
+// end of the input can still be told apart from other invalid ones.
+func unbuffered(r io.Reader) (io.RuneReader, bool) {
+	switch r := r.(type) {
+	case *bufio.Reader:
+		return r, true
+	case io.ByteReader:
+		return &byteReader{r: r}, true
+	case io.RuneReader:
+		return r, true
+	}
+	return nil, false
+}
+
+// Decodes UTF-8 from a reader one byte at a time, the same way a
+// bufio.Reader would.
+type byteReader struct {
+	r         io.ByteReader
+	buf       [utf8.UTFMax]byte
+	n         int   // bytes read but not yet decoded
+	err       error // error from the reader, returned once buf is empty
+	truncated bool  // whether the last rune was cut off by the end of input
+}
+
+// Reads the next UTF-8 encoded character.
+func (b *byteReader) ReadRune() (rune, int, error) {
+	for b.n < utf8.UTFMax && !utf8.FullRune(b.buf[:b.n]) && b.err == nil {
+		c, err := b.r.ReadByte()
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				pda.isEOF = true
-				pda.isRunning = false
-			} else {
-				return &Value{}, err
-			}
+			b.err = err
+			break
 		}
-		if r == unicode.ReplacementChar {
-			return &Value{}, fmt.Errorf("%w: invalid UTF-8 character at %d", ErrParse, pda.pos)
+		if b.n == 0 && c < utf8.RuneSelf {
+			return rune(c), 1, nil
 		}
-		if err := pda.consumeCharacter(r); err != nil {
+		b.buf[b.n] = c
+		b.n++
+	}
+	if b.n == 0 {
+		return 0, 0, b.err
+	}
+	b.truncated = b.err == io.EOF && !utf8.FullRune(b.buf[:b.n])
+	r, size := utf8.DecodeRune(b.buf[:b.n])
+	b.n = copy(b.buf[:], b.buf[size:b.n])
+	return r, size, nil
+}
+
+// Runs the PDA over the whole input.
+func (p *parser) parse(b io.RuneReader) (*Value, error) {
+	// main loop
+	for p.isRunning {
+		if err := p.next(b); err != nil {
 			return &Value{}, err
 		}
+	}
+	return p.valueStack[0], nil
+}
+
+// Creates a parser ready to read a single value.
+func newParser(options ParseOptions) *parser {
+	pda := &parser{}
+	pda.reset(options)
+	return pda
+}
 
-		pda.pos += n
+// Gets the parser ready to read a new value, keeping the memory
+// allocated for its stacks and buffer.
+func (p *parser) reset(options ParseOptions) {
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = depth
+	}
+	// Let go of values from the last parse so they can be collected
+	for i := range p.valueStack {
+		p.valueStack[i] = nil
+	}
+	if len(p.valueStack) == 0 {
+		p.valueStack = []*Value{nil}
+	}
+	for i := range p.free {
+		p.free[i] = nil
+	}
+	for k := range p.interned {
+		delete(p.interned, k)
+	}
+	for i := range p.freeArrays {
+		p.freeArrays[i] = nil
+	}
+	for i := range p.freeObjects {
+		p.freeObjects[i] = nil
+	}
+	*p = parser{
+		isRunning:   true,
+		isEOF:       false,
+		state:       sr,
+		modeTop:     -1,
+		valueTop:    -1,
+		line:        1,
+		column:      1,
+		modeStack:   p.modeStack,
+		valueStack:  p.valueStack,
+		indexes:     p.indexes[:0],
+		buffer:      p.buffer[:0],
+		comment:     p.comment[:0],
+		interned:    p.interned,
+		free:        p.free[:0],
+		freeArrays:  p.freeArrays[:0],
+		freeObjects: p.freeObjects[:0],
+		options:     options,
+	}
+	p.pushMode(modeDone)
+}
+
+// Reads one character from the input and runs it through the PDA.
+func (p *parser) next(b io.RuneReader) error {
+	r, n, err := b.ReadRune()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			p.isEOF = true
+			p.isRunning = false
+		} else {
+			return err
+		}
+	}
+	if r == utf8.RuneError && n == 1 && isTruncated(b) {
+		return p.errorf(ErrInvalidUTF8, "incomplete UTF-8 sequence")
+	}
+	return p.step(r, n)
+}
+
+// Whether the invalid character just read from a reader is the start of a
+// character that was cut off by the end of the input. Only a bufio.Reader
+// or a byteReader can be checked, since the bytes after it are needed.
+func isTruncated(b io.RuneReader) bool {
+	if br, ok := b.(*byteReader); ok {
+		return br.truncated
+	}
+	br, ok := b.(*bufio.Reader)
+	if !ok || br.UnreadRune() != nil {
+		return false
+	}
+	rest, err := br.Peek(utf8.UTFMax)
+	br.ReadRune()
+	return err == io.EOF && !utf8.FullRune(rest)
+}
+
+// Runs one character that was n bytes wide through the PDA.
+// A byte order mark at the very start of the input is skipped.
+func (p *parser) step(r rune, n int) error {
+	if p.options.MaxBytes > 0 && p.pos+n > p.options.MaxBytes {
+		p.isRunning = false
+		return p.errorf(ErrTooLarge, "input exceeds size limit of %d bytes", p.options.MaxBytes)
+	}
+	if r == byteOrderMark && p.pos == 0 {
+		p.pos += n
+		return nil
+	}
+	if r == utf8.RuneError && n == 1 {
+		// A real U+FFFD in the input takes up three bytes
+		return p.errorf(ErrInvalidUTF8, "invalid UTF-8 character")
+	}
+	for {
+		if err := p.consumeCharacter(r); err != nil {
+			return err
+		}
+		if !p.reconsume {
+			break
+		}
+		// The character ended a comment and still needs to be handled
+		p.reconsume = false
+	}
+	if p.isEOF && !p.isComplete() {
+		return p.incomplete()
+	}
+	if p.isEOF && !p.validate {
+		v, err := p.revive("", p.valueStack[0])
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			v = NewNull()
+		}
+		p.valueStack[0] = v
 	}
-	return pda.valueStack[0], nil
+
+	p.advance(r, n)
+	return nil
 }
 
 // Parses a JSON value from a string. If it cannot read a valid value,
@@ -518,5 +1496,123 @@ func ParseString(s string) (*Value, error) {
 // it returns a null value and a non-nil error.
 // Returns the parsed value and nil error otherwise.
 func ParseBytes(b []byte) (*Value, error) {
-	return ParseString(string(b))
+	return parseBytes(b, DefaultParseOptions())
+}
+
+// Parses a JSON value from a string like ParseString, but panics if the
+// string is not valid JSON. It is meant for known-good literals such as
+// test fixtures and package-level variables.
+func MustParse(s string) *Value {
+	v, err := ParseString(s)
+	if err != nil {
+		panic(fmt.Sprintf("json: MustParse(%q): %v", s, err))
+	}
+	return v
+}
+
+// Parses a JSON value from a byte slice like ParseBytes, but panics if the
+// bytes are not valid JSON. See MustParse.
+func MustParseBytes(b []byte) *Value {
+	v, err := ParseBytes(b)
+	if err != nil {
+		panic(fmt.Sprintf("json: MustParseBytes(%q): %v", b, err))
+	}
+	return v
+}
+
+// Runs the parser directly over a byte slice, which avoids copying it
+// and the overhead of going through a reader.
+func parseBytes(b []byte, options ParseOptions) (*Value, error) {
+	return newParser(options).parseBytes(b)
+}
+
+// Runs the PDA directly over a byte slice.
+func (p *parser) parseBytes(b []byte) (*Value, error) {
+	// main loop
+	for p.isRunning {
+		if err := p.nextByte(b); err != nil {
+			return &Value{}, err
+		}
+	}
+	return p.valueStack[0], nil
+}
+
+// Runs the character at the current position in a byte slice through the PDA.
+func (p *parser) nextByte(b []byte) error {
+	var r rune
+	var n int
+	if p.pos >= len(b) {
+		p.isEOF = true
+		p.isRunning = false
+	} else if b[p.pos] < utf8.RuneSelf {
+		r, n = rune(b[p.pos]), 1
+	} else {
+		r, n = utf8.DecodeRune(b[p.pos:])
+		if r == utf8.RuneError && n == 1 && !utf8.FullRune(b[p.pos:]) {
+			return p.errorf(ErrInvalidUTF8, "incomplete UTF-8 sequence")
+		}
+	}
+	return p.step(r, n)
+}
+
+// Parses the first JSON value in a byte slice and reports how many bytes it
+// took up, so that values written back to back, such as {}{}{}, can be read
+// one at a time by calling it again on the rest of the slice. Whitespace and
+// comments before the value are included in the count. A number at the top
+// level ends at the first character that can't be part of it, which must be
+// whitespace or the end of the slice, and that character is counted too.
+// If it cannot read a valid value, it returns a null value, 0, and a
+// non-nil error.
+func ParsePrefix(b []byte) (*Value, int, error) {
+	pda := newParser(DefaultParseOptions())
+	for pda.isRunning && !pda.isComplete() {
+		if err := pda.nextByte(b); err != nil {
+			return &Value{}, 0, err
+		}
+	}
+	return pda.valueStack[0], pda.pos, nil
+}
+
+// Parses the first JSON value from a Reader and gets a Reader positioned
+// right after it, so that whatever follows the value on the stream, such as
+// the rest of a frame, can still be read. Like ParsePrefix, a number at the
+// top level ends at the first character that can't be part of it, which must
+// be whitespace or the end of the input, and that character is consumed too.
+// If r can be read a rune or a byte at a time, nothing past the value is read
+// from it and the Reader returned is r itself. Otherwise it is buffered, and
+// the Reader returned holds what was read ahead.
+// If it cannot read a valid value, it returns a null value and a non-nil error.
+func ParseReader(r io.Reader) (*Value, io.Reader, error) {
+	rr, ok := unbuffered(r)
+	rest := r
+	if !ok {
+		br := bufio.NewReader(r)
+		rr, rest = br, br
+	}
+	pda := newParser(DefaultParseOptions())
+	for pda.isRunning && !pda.isComplete() {
+		if err := pda.next(rr); err != nil {
+			return &Value{}, rest, err
+		}
+	}
+	return pda.valueStack[0], rest, nil
+}
+
+// Reports whether a byte slice holds a single well-formed JSON value,
+// accepting the same input as ParseBytes. It runs the parser without
+// building the value, so it is faster than parsing and discarding the result.
+func Valid(b []byte) bool {
+	pda := newParser(DefaultParseOptions())
+	pda.validate = true
+	_, err := pda.parseBytes(b)
+	return err == nil
+}
+
+// Reports whether a string holds a single well-formed JSON value,
+// accepting the same input as ParseString. See Valid.
+func ValidString(s string) bool {
+	pda := newParser(DefaultParseOptions())
+	pda.validate = true
+	_, err := pda.parse(strings.NewReader(s))
+	return err == nil
 }
@@ -1,9 +1,21 @@
 package json
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+	"unicode/utf16"
+	"unsafe"
 )
 
 type mockFileErrorOnRead struct{}
@@ -19,6 +31,9 @@ func equals(a, b *Value) bool {
 	case Boolean:
 		return b.jsonType == Boolean && a.booleanValue == b.booleanValue
 	case Integer:
+		if a.bigValue != nil || b.bigValue != nil {
+			return b.jsonType == Integer && a.bigInt().Cmp(b.bigInt()) == 0
+		}
 		return b.jsonType == Integer && a.integerValue == b.integerValue
 	case Number:
 		return b.jsonType == Number && a.numberValue == b.numberValue
@@ -59,6 +74,11 @@ func equals(a, b *Value) bool {
 	return false
 }
 
+func bigIntFromString(s string) *big.Int {
+	b, _ := new(big.Int).SetString(s, 10)
+	return b
+}
+
 func TestJSONParseValid(t *testing.T) {
 
 	type testdata struct{ input string }
@@ -542,6 +562,18 @@ func TestJSONParseValues(t *testing.T) {
 			input:    `-500`,
 			expected: &Value{jsonType: Integer, integerValue: -500},
 		},
+		{
+			input:    `123456789012345678901234567890`,
+			expected: &Value{jsonType: Integer, bigValue: bigIntFromString("123456789012345678901234567890")},
+		},
+		{
+			input:    `[-123456789012345678901234567890]`,
+			expected: &Value{jsonType: Array, arrayValue: []*Value{{jsonType: Integer, bigValue: bigIntFromString("-123456789012345678901234567890")}}},
+		},
+		{
+			input:    `9223372036854775807`,
+			expected: &Value{jsonType: Integer, integerValue: 9223372036854775807},
+		},
 		{
 			input:    `-500.75e-3`,
 			expected: &Value{jsonType: Number, numberValue: -500.75e-3},
@@ -721,3 +753,1464 @@ func TestParseBytes(t *testing.T) {
 		t.Errorf("expected %v\ngot %v", expected, actual)
 	}
 }
+
+func TestParseErrorPosition(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		line   int
+		column int
+		offset int
+	}{
+		{`q`, 1, 1, 0},
+		{`[1, q]`, 1, 5, 4},
+		{"{\n\t\"a\": q\n}", 2, 7, 8},
+		{"[\n\n  \"世界\" q]", 3, 8, 14},
+		{"[\n\n  \"世界\" \xff]", 3, 8, 14},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseString(test.input)
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError got %v", err)
+			}
+			if parseErr.Line != test.line || parseErr.Column != test.column || parseErr.Offset != test.offset {
+				t.Errorf("expected %v:%v (%v) got %v:%v (%v)", test.line, test.column, test.offset, parseErr.Line, parseErr.Column, parseErr.Offset)
+			}
+		})
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	for _, test := range []struct {
+		input   string
+		options ParseOptions
+		offset  int
+	}{
+		{`// comment` + "\nnull", ParseOptions{AllowTrailingCommas: true}, 0},
+		{`[null /* comment */]`, ParseOptions{AllowTrailingCommas: true}, 6},
+		{`[null,]`, ParseOptions{AllowComments: true}, 6},
+		{`{"a": null, }`, ParseOptions{AllowComments: true}, 12},
+		{`[[], {},]`, ParseOptions{}, 8},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseWithOptions(strings.NewReader(test.input), test.options)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError got %v", err)
+			}
+			if parseErr.Offset != test.offset {
+				t.Errorf("expected %v got %v", test.offset, parseErr.Offset)
+			}
+
+			if _, err := ParseWithOptions(strings.NewReader(test.input), DefaultParseOptions()); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+		})
+	}
+
+	for _, input := range []string{`[]`, `{}`, `[null, [], {}]`, `{"a": [], "b": {}}`, `"// not a comment"`} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseWithOptions(strings.NewReader(input), ParseOptions{}); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRejectDuplicateKeys(t *testing.T) {
+	options := DefaultParseOptions()
+	options.RejectDuplicateKeys = true
+	for _, test := range []struct {
+		input  string
+		key    string
+		offset int
+		line   int
+		column int
+	}{
+		{`{"a":1,"a":2}`, `"a"`, 7, 1, 8},
+		{`{"a": 1, "a": 2}`, `"a"`, 9, 1, 10},
+		{`{"a": 1, "b": 2, "a": 3, "c": 4}`, `"a"`, 17, 1, 18},
+		{`[{"a": {"b": 1, "b": [2]}}]`, `"b"`, 16, 1, 17},
+		{"{\n  \"é\": 1,\n  \"é\": {}\n}", `"é"`, 15, 3, 3},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseWithOptions(strings.NewReader(test.input), options)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError got %v", err)
+			}
+			if !errors.Is(err, ErrParse) || !errors.Is(err, ErrDuplicateKey) {
+				t.Errorf("expected %v and %v got %v", ErrParse, ErrDuplicateKey, err)
+			}
+			if parseErr.Offset != test.offset {
+				t.Errorf("expected %v got %v", test.offset, parseErr.Offset)
+			}
+			if parseErr.Line != test.line || parseErr.Column != test.column {
+				t.Errorf("expected %v:%v got %v:%v", test.line, test.column, parseErr.Line, parseErr.Column)
+			}
+			if !strings.Contains(err.Error(), test.key) {
+				t.Errorf("expected %v in error got %v", test.key, err)
+			}
+		})
+	}
+
+	for _, input := range []string{`{}`, `{"a": 1, "b": {"a": 2}}`, `[{"a": 1}, {"a": 2}]`} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseWithOptions(strings.NewReader(input), options); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		maxDepth int
+		valid    bool
+	}{
+		{`[[[]]]`, 3, true},
+		{`[[[]]]`, 2, false},
+		{`{"a": {"b": []}}`, 3, true},
+		{`{"a": {"b": []}}`, 2, false},
+		{`{"a": [1, {"b": 2}]}`, 3, true},
+		{`{"a": [1, {"b": 2}]}`, 2, false},
+		{`null`, 1, true},
+		{`[]`, 1, true},
+		{`{}`, 1, true},
+		{`[[]]`, 1, false},
+		{`[/* a */ [ // b
+		]]`, 2, true},
+		{strings.Repeat("[", 5000) + strings.Repeat("]", 5000), 5000, true},
+		{strings.Repeat("[", 5000) + strings.Repeat("]", 5000), 4999, false},
+		{strings.Repeat("[", 5000) + strings.Repeat("]", 5000), 0, false},
+		{strings.Repeat("[", 1024) + strings.Repeat("]", 1024), -1, true},
+		{strings.Repeat("[", 1025) + strings.Repeat("]", 1025), -1, false},
+		{`{/*c*/"a":1}`, 1, true},
+		{`{"a": {/*c*/}}`, 2, true},
+		{`{"a": {/*c*/"b": {}}}`, 2, false},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			options := DefaultParseOptions()
+			options.MaxDepth = test.maxDepth
+			val, err := ParseWithOptions(strings.NewReader(test.input), options)
+			if test.valid && err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if !test.valid && !errors.Is(err, ErrDepthExceeded) {
+				t.Errorf("expected %v got %v", ErrDepthExceeded, err)
+			}
+			if test.valid && test.maxDepth > 0 && val.MaxDepth() > test.maxDepth {
+				t.Errorf("expected at most %v got %v", test.maxDepth, val.MaxDepth())
+			}
+		})
+	}
+
+	// Comments at the deepest level don't cut the value short
+	val, err := ParseWithOptions(strings.NewReader(`{/*c*/"a":1}`), ParseOptions{AllowComments: true, MaxDepth: 1})
+	if expected := MustParse(`{"a": 1}`); err != nil || !equals(expected, val) {
+		t.Errorf("expected %v got %v, %v", expected, val, err)
+	}
+	deep := strings.Repeat(`{"a": `, 1023) + `{/*c*/"b": 1}` + strings.Repeat("}", 1023)
+	val, err = Parse(strings.NewReader(deep))
+	if err != nil || val.MaxDepth() != 1024 {
+		t.Errorf("expected depth %v got %v, %v", 1024, val.MaxDepth(), err)
+	}
+}
+
+type mockSlowReader struct {
+	cancel func()
+}
+
+func (f mockSlowReader) Read(p []byte) (n int, err error) {
+	f.cancel()
+	p[0] = ' '
+	return 1, nil
+}
+
+func TestParseContext(t *testing.T) {
+	val, err := ParseContext(context.Background(), strings.NewReader(`[1, 2]`))
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := &Value{jsonType: Array, arrayValue: []*Value{
+		{jsonType: Integer, integerValue: 1},
+		{jsonType: Integer, integerValue: 2},
+	}}
+	if !equals(expected, val) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseContext(ctx, strings.NewReader(`[1, 2]`)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	if _, err := ParseContext(ctx, mockSlowReader{cancel}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+
+	// A deadline takes effect as soon as a slow read returns
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := ParseContext(ctx, mockTrickleReader{time.Millisecond}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected at most %v got %v", time.Second, elapsed)
+	}
+
+	// Options are used like in ParseWithOptions
+	if _, err := ParseContextWithOptions(context.Background(), strings.NewReader(`[1 /* x */]`), ParseOptions{}); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	options := ParseOptions{AllowComments: true}
+	val, err = ParseContextWithOptions(context.Background(), strings.NewReader(`[1 /* x */]`), options)
+	if err != nil || val.Index(0).IntOr(0) != 1 {
+		t.Errorf("expected %v got %v %v", 1, val, err)
+	}
+}
+
+// Reads endless whitespace, one byte at a time.
+type mockTrickleReader struct {
+	delay time.Duration
+}
+
+func (f mockTrickleReader) Read(p []byte) (n int, err error) {
+	time.Sleep(f.delay)
+	p[0] = ' '
+	return 1, nil
+}
+
+func TestParseBytesMatchesParse(t *testing.T) {
+	for _, input := range []string{
+		`{"a": [1, 2.5e3, "世界", true, null]}`,
+		"[\"\xff\"]",
+		`[1, 2`,
+		`"Hello, 世界" x`,
+		`123`,
+		``,
+	} {
+		t.Run(input, func(t *testing.T) {
+			expected, expectedErr := Parse(strings.NewReader(input))
+			actual, err := ParseBytes([]byte(input))
+			if !equals(expected, actual) {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if fmt.Sprint(expectedErr) != fmt.Sprint(err) {
+				t.Errorf("expected %v got %v", expectedErr, err)
+			}
+		})
+	}
+}
+
+var benchmarkInput = []byte(`{
+	"null": null,
+	"true": true,
+	"false": false,
+	"number": -105.754e+7,
+	"string": "-10\"\n\r\f\b\t\\\/Ư",
+	"array": [null, true, -10.55e-15, "-10\"\n\r\f\b\t\\\/Ư"],
+	"object": {
+		"null": null,
+		"true": true,
+		"false": false,
+		"number": -105.754e+7,
+		"string": "Hello, 世界",
+		"array": [null, true, -10.55e-15, "-10\"\n\r\f\b\t\\\/Ư"]
+	}
+}`)
+
+func BenchmarkParse(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		Parse(bytes.NewReader(benchmarkInput))
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		ParseBytes(benchmarkInput)
+	}
+}
+
+func TestParseLongLiterals(t *testing.T) {
+	long := strings.Repeat("Hello, 世界 ", 100000)
+	v, err := ParseString(`["` + long + `", ` + strings.Repeat("9", 1000) + `]`)
+	if err != nil {
+		t.Fatalf("expected %v got %v", nil, err)
+	}
+	s, _ := v.arrayValue[0].AsString()
+	if s != long {
+		t.Errorf("expected string of length %v got %v", len(long), len(s))
+	}
+	if n := v.arrayValue[1].bigValue.String(); n != strings.Repeat("9", 1000) {
+		t.Errorf("expected %v got %v", strings.Repeat("9", 1000), n)
+	}
+}
+
+func BenchmarkParseLongString(b *testing.B) {
+	input := []byte(`"` + strings.Repeat("a", 1<<20) + `"`)
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		ParseBytes(input)
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected bool
+	}{
+		{`null`, true},
+		{`true`, true},
+		{`fals`, false},
+		{`-12.5e+3`, true},
+		{`-`, false},
+		{`1e400`, false},
+		{`[123456789012345678901234567890]`, true},
+		{`"a\"bé"`, true},
+		{`"a\x"`, false},
+		{`[]`, true},
+		{`[1, "2", [3, {}], {"a": null}]`, true},
+		{`[1, 2,]`, true},
+		{`[1 2]`, false},
+		{`{"a": 1, "b": {"c": [true]}}`, true},
+		{`{"a" 1}`, false},
+		{`{"a": 1]`, false},
+		{`{1: 2}`, false},
+		{`[1] // comment`, true},
+		{`[1] x`, false},
+		{"[\"\xff\"]", false},
+		{string(benchmarkInput), true},
+		{strings.Repeat("[", 2000) + strings.Repeat("]", 2000), false},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			if actual := Valid([]byte(test.input)); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if actual := ValidString(test.input); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			_, err := ParseString(test.input)
+			if actual := err == nil; actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	// Numbers are checked without building a value for each of them
+	one := []byte(`[-1.5e10, 12]`)
+	many := []byte("[" + strings.Repeat(`-1.5e10, 12, `, 1000) + "0]")
+	expected := testing.AllocsPerRun(10, func() { Valid(one) })
+	if actual := testing.AllocsPerRun(10, func() { Valid(many) }); actual != expected {
+		t.Errorf("expected %v allocations got %v", expected, actual)
+	}
+}
+
+func BenchmarkValid(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		Valid(benchmarkInput)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if v := MustParse(`[1, 2]`); v.Index(1).IntOr(0) != 2 {
+		t.Errorf("expected %v got %v", "[1, 2]", v)
+	}
+	if v := MustParseBytes([]byte(`{"a": true}`)); !v.Key("a").BoolOr(false) {
+		t.Errorf("expected %v got %v", `{"a": true}`, v)
+	}
+
+	for _, test := range []struct {
+		name string
+		f    func()
+	}{
+		{"MustParse", func() { MustParse(`[1,`) }},
+		{"MustParseBytes", func() { MustParseBytes([]byte(`x`)) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("expected panic got none")
+				}
+			}()
+			test.f()
+		})
+	}
+}
+
+func TestParseEscapes(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{`"abc"`, "abc"},
+		{`""`, ""},
+		{`"\"\\\/\b\f\n\r\t"`, "\"\\/\b\f\n\r\t"},
+		{`"\\/"`, `\/`},
+		{`"\\\/"`, `\/`},
+		{`"a\\\\/b"`, `a\\/b`},
+		{`"Aé世"`, "Aé世"},
+		{`"\u0000"`, "\x00"},
+		{`"\u00e9\u4E16"`, "é世"},
+		{`"\ud83d\ude00"`, "\U0001f600"},
+		{`"\ud83d\ude00!"`, "\U0001f600!"},
+		{`"\uD83D\uDE00"`, "\U0001f600"},
+		{`"\udbff\udfff"`, "\U0010ffff"},
+		{`"世界"`, "世界"},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			v, err := ParseString(test.input)
+			if err != nil {
+				t.Fatalf("expected %v got %v", nil, err)
+			}
+			if actual, _ := v.AsString(); actual != test.expected {
+				t.Errorf("expected %q got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseInvalidSurrogates(t *testing.T) {
+	for _, input := range []string{
+		`"\ud83d"`,
+		`"\ude00"`,
+		`"\ud83dx"`,
+		`"\ud83d\u0041"`,
+		`"\ude00\ud83d"`,
+		`"\ud83d\ud83d\ude00"`,
+		`["a", {"\ud83d": 1}]`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseString(input); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if Valid([]byte(input)) {
+				t.Errorf("expected %v got %v", false, true)
+			}
+		})
+	}
+}
+
+func TestParseUnrepresentableNumbers(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected error
+	}{
+		{`1e400`, ErrParse},
+		{`-1.5e999`, ErrParse},
+		{`[1, 1e400]`, ErrParse},
+		{`{"a": 1e400}`, ErrParse},
+		{`[1e400, 2]`, ErrParse},
+		{`1e-400`, nil},
+		{`1e308`, nil},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseString(test.input)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+			if actual := Valid([]byte(test.input)); actual != (test.expected == nil) {
+				t.Errorf("expected %v got %v", test.expected == nil, actual)
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected []string
+		err      error
+	}{
+		{`{}{}{}`, []string{`{}`, `{}`, `{}`}, nil},
+		{`[1,2]{"a":"b"}"c"`, []string{`[1, 2]`, `{"a": "b"}`, `"c"`}, nil},
+		{` 1 2 3`, []string{`1`, `2`, `3`}, nil},
+		{`true/**/null // x
+		"世界"`, []string{`true`, `null`, `"世界"`}, nil},
+		{`{}[`, []string{`{}`}, ErrParse},
+		{`{}x`, []string{`{}`}, ErrParse},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			b := []byte(test.input)
+			actual := []string{}
+			var err error
+			for len(b) > 0 {
+				var v *Value
+				var n int
+				v, n, err = ParsePrefix(b)
+				if err != nil {
+					break
+				}
+				actual = append(actual, v.String())
+				b = b[n:]
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if _, n, _ := ParsePrefix([]byte(`  {"a": [1]} {}`)); n != 12 {
+		t.Errorf("expected %v got %v", 12, n)
+	}
+	if _, n, err := ParsePrefix([]byte(`[1,`)); n != 0 || err == nil {
+		t.Errorf("expected %v got %v %v", 0, n, err)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+		rest     string
+		err      error
+	}{
+		{"{\"len\": 3}\x00\xff\x01", `{"len": 3}`, "\x00\xff\x01", nil},
+		{` ["a", true]["b"]`, `["a", true]`, `["b"]`, nil},
+		{"null\n\xff", `null`, "\n\xff", nil},
+		{"12 \xff", `12`, "\xff", nil},
+		{`"abc"`, `"abc"`, ``, nil},
+		{`7`, `7`, ``, nil},
+		{`{"a": `, `null`, ``, ErrUnexpectedEOF},
+		{``, `null`, ``, ErrUnexpectedEOF},
+	} {
+		for name, wrap := range map[string]func(io.Reader) io.Reader{
+			"bytes":  func(r io.Reader) io.Reader { return r },
+			"bufio":  func(r io.Reader) io.Reader { return bufio.NewReader(r) },
+			"reader": func(r io.Reader) io.Reader { return iotest.HalfReader(r) },
+		} {
+			t.Run(name+" "+test.input, func(t *testing.T) {
+				val, rest, err := ParseReader(wrap(strings.NewReader(test.input)))
+				if !errors.Is(err, test.err) {
+					t.Errorf("expected %v got %v", test.err, err)
+				}
+				if val.String() != test.expected {
+					t.Errorf("expected %v got %v", test.expected, val)
+				}
+				if err != nil {
+					return
+				}
+				b, err := io.ReadAll(rest)
+				if err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+				if string(b) != test.rest {
+					t.Errorf("expected %q got %q", test.rest, b)
+				}
+			})
+		}
+	}
+
+	// Nothing past the value is read from a reader that can read bytes
+	r := strings.NewReader(`{"a": 1}{"b": 2}`)
+	if _, rest, _ := ParseReader(r); rest != r || r.Len() != 8 {
+		t.Errorf("expected %v got %v", 8, r.Len())
+	}
+}
+
+func TestParseEmptyInput(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`   `,
+		"\n\t",
+		`// just a comment`,
+		"// just a comment\n",
+		`/* just a comment */`,
+		` /* a */ // b`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseString(input)
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "unexpected end of input") {
+				t.Errorf("expected %v got %v", "unexpected end of input", err)
+			}
+			if Valid([]byte(input)) {
+				t.Errorf("expected %v got %v", false, true)
+			}
+		})
+	}
+}
+
+func TestParseByteOrderMark(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected *Value
+		err      error
+	}{
+		{"\xef\xbb\xbf[1]", &Value{jsonType: Array, arrayValue: []*Value{{jsonType: Integer, integerValue: 1}}}, nil},
+		{"\xef\xbb\xbf \"a\"", &Value{jsonType: String, stringValue: "a"}, nil},
+		{"\xef\xbb\xbf", &Value{}, ErrParse},
+		{"\xef\xbb\xbf\xef\xbb\xbf1", &Value{}, ErrParse},
+		{" \xef\xbb\xbf1", &Value{}, ErrParse},
+		{"[\xef\xbb\xbf1]", &Value{}, ErrParse},
+		{"\"\xef\xbb\xbf\"", &Value{jsonType: String, stringValue: "\ufeff"}, nil},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			actual, err := ParseString(test.input)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			actual, err = ParseBytes([]byte(test.input))
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	_, err := ParseString("\xef\xbb\xbf[x]")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Offset != 4 || parseErr.Column != 2 {
+		t.Errorf("expected %v got %v", "column 2 (byte 4)", err)
+	}
+}
+
+func TestParseErrorKinds(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected error
+	}{
+		{`[1}`, ErrUnmatchedBrace},
+		{`{"a": 1]`, ErrUnmatchedBrace},
+		{`[[1, 2}]`, ErrUnmatchedBrace},
+		{strings.Repeat("[", 2000), ErrDepthExceeded},
+		{strings.Repeat(`{"a":`, 2000), ErrDepthExceeded},
+		{"[\"\xff\"]", ErrInvalidUTF8},
+		{``, ErrUnexpectedEOF},
+		{`[1,`, ErrUnexpectedEOF},
+		{`{"a":`, ErrUnexpectedEOF},
+		{`"abc`, ErrUnexpectedEOF},
+		{`[x]`, ErrParse},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseString(test.input)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			for _, other := range []error{ErrUnmatchedBrace, ErrDepthExceeded, ErrInvalidUTF8, ErrUnexpectedEOF, ErrTooLarge} {
+				if other != test.expected && errors.Is(err, other) {
+					t.Errorf("expected %v got %v", test.expected, other)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePreserveComments(t *testing.T) {
+	options := DefaultParseOptions()
+	options.PreserveComments = true
+	input := `// leading
+	/* block */ {
+		// before key
+		"a": /* before value */ 1,
+		"b": [
+			2 /* after two */, // before three
+			3/* tight */, 4
+			// dangling
+		],
+		"c": "d" // end
+	} // trailing`
+	val, err := ParseWithOptions(strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for _, test := range []struct {
+		path     string
+		expected []string
+	}{
+		{"", []string{"// leading", "/* block */"}},
+		{"/a", []string{"// before key", "/* before value */"}},
+		{"/b", nil},
+		{"/b/0", nil},
+		{"/b/1", []string{"/* after two */", "// before three"}},
+		{"/b/2", []string{"/* tight */"}},
+		{"/c", nil},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			v, _ := val.Pointer(test.path)
+			actual := v.Comments()
+			if strings.Join(actual, "|") != strings.Join(test.expected, "|") {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if n := val.Get("b", 1).IntOr(0); n != 3 {
+		t.Errorf("expected %v got %v", 3, n)
+	}
+
+	val, _ = ParseString(input)
+	if comments := val.Comments(); comments != nil {
+		t.Errorf("expected %v got %v", nil, comments)
+	}
+	if c := MustParse(`[1/**/]`).Index(0).IntOr(0); c != 1 {
+		t.Errorf("expected %v got %v", 1, c)
+	}
+	if _, err := ParseString(`1/**/2`); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+}
+
+func TestParseNumberMode(t *testing.T) {
+	huge := bigIntFromString("123456789012345678901234567890")
+	for _, test := range []struct {
+		input    string
+		mode     NumberMode
+		expected *Value
+	}{
+		{`5`, NumbersBySyntax, &Value{jsonType: Integer, integerValue: 5}},
+		{`1e2`, NumbersBySyntax, &Value{jsonType: Number, numberValue: 100}},
+		{`1e2`, PreferInteger, &Value{jsonType: Integer, integerValue: 100}},
+		{`-5.0`, PreferInteger, &Value{jsonType: Integer, integerValue: -5}},
+		{`1.5e1`, PreferInteger, &Value{jsonType: Integer, integerValue: 15}},
+		{`1.5`, PreferInteger, &Value{jsonType: Number, numberValue: 1.5}},
+		{`1e30`, PreferInteger, &Value{jsonType: Number, numberValue: 1e30}},
+		{`1.0000000000000000001`, PreferInteger, &Value{jsonType: Number, numberValue: 1}},
+		{`9223372036854775807.0`, PreferInteger, &Value{jsonType: Number, numberValue: 9223372036854775807}},
+		{`-9223372036854775808.0`, PreferInteger, &Value{jsonType: Integer, integerValue: math.MinInt64}},
+		{`123456789012345678901234567890`, PreferInteger, &Value{jsonType: Integer, bigValue: huge}},
+		{`5`, AlwaysFloat, &Value{jsonType: Number, numberValue: 5}},
+		{`123456789012345678901234567890`, AlwaysFloat, &Value{jsonType: Number, numberValue: 1.2345678901234568e29}},
+		{`1e2`, AlwaysFloat, &Value{jsonType: Number, numberValue: 100}},
+		{`5`, RawNumbers, &Value{jsonType: String, stringValue: "5"}},
+		{`-1.50e2`, RawNumbers, &Value{jsonType: String, stringValue: "-1.50e2"}},
+		{`1e400`, RawNumbers, &Value{jsonType: String, stringValue: "1e400"}},
+	} {
+		t.Run(fmt.Sprintf("%s %d", test.input, test.mode), func(t *testing.T) {
+			options := DefaultParseOptions()
+			options.NumberMode = test.mode
+			actual, err := ParseWithOptions(strings.NewReader(test.input), options)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %#v got %#v", test.expected, actual)
+			}
+			if test.mode != RawNumbers {
+				if raw, _ := actual.AsRawNumber(); raw != test.input {
+					t.Errorf("expected %v got %v", test.input, raw)
+				}
+			}
+		})
+	}
+
+	options := DefaultParseOptions()
+	options.NumberMode = AlwaysFloat
+	options.ExactDecimals = true
+	val, err := ParseWithOptions(strings.NewReader(`[5, 0.1]`), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for i, expected := range []string{"5", "0.1"} {
+		d, err := val.Index(i).AsDecimal()
+		if err != nil || d.String() != expected {
+			t.Errorf("expected %v got %v, %v", expected, d, err)
+		}
+	}
+
+	options.NumberMode = PreferInteger
+	if _, err := ParseWithOptions(strings.NewReader(`1e400`), options); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+}
+
+func TestParseControlCharacters(t *testing.T) {
+	lenient := DefaultParseOptions()
+	lenient.AllowControlCharacters = true
+	for _, test := range []struct {
+		input    string
+		expected string
+		offset   int
+	}{
+		{"\"a\nb\"", "a\nb", 2},
+		{"\"\t\"", "\t", 1},
+		{"\"a\rb\"", "a\rb", 2},
+		{"\"\x00\"", "\x00", 1},
+		{"\"\x1f\"", "\x1f", 1},
+		{"{\"a\x01\": 1}", "", 3},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseString(test.input)
+			if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), "control character") {
+				t.Errorf("expected %v got %v", "unescaped control character", err)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || parseErr.Offset != test.offset {
+				t.Errorf("expected %v got %v", test.offset, err)
+			}
+			if Valid([]byte(test.input)) {
+				t.Errorf("expected %v got %v", false, true)
+			}
+
+			val, err := ParseWithOptions(strings.NewReader(test.input), lenient)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if test.expected != "" && val.StringOr("") != test.expected {
+				t.Errorf("expected %q got %q", test.expected, val.StringOr(""))
+			}
+		})
+	}
+
+	// Escaped control characters and DEL are fine either way
+	if val, err := ParseString(`"\u0001\n` + "\x7f" + `"`); err != nil || val.StringOr("") != "\x01\n\x7f" {
+		t.Errorf("expected %q got %v, %v", "\x01\n\x7f", val, err)
+	}
+	// Outside of strings, tabs and newlines are still whitespace
+	if _, err := ParseString("[\t1,\r\n2]"); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if _, err := ParseWithOptions(strings.NewReader("[\x01]"), lenient); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+}
+
+func TestParseReviver(t *testing.T) {
+	input := `{"a": [1, "2006-01-02T15:04:05Z", {"secret": "x", "b": 2}], "secret": true, "c": null}`
+	var seen []string
+	options := DefaultParseOptions()
+	options.Reviver = func(key string, v *Value) (*Value, error) {
+		seen = append(seen, key+"="+v.String())
+		if key == "secret" {
+			return nil, nil
+		}
+		if _, err := v.AsTime(); err == nil {
+			return NewString("<time>"), nil
+		}
+		if i, err := v.AsInteger(); err == nil {
+			return NewInt(i * 10), nil
+		}
+		return v, nil
+	}
+	val, err := ParseWithOptions(strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if expected := MustParse(`{"a": [10, "<time>", {"b": 20}], "c": null}`); !equals(expected, val) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	expected := []string{
+		`0=1`,
+		`1="2006-01-02T15:04:05Z"`,
+		`secret="x"`,
+		`b=2`,
+		`2={"b": 20}`,
+		`a=[10, "<time>", {"b": 20}]`,
+		`secret=true`,
+		`c=null`,
+		`={"a": [10, "<time>", {"b": 20}], "c": null}`,
+	}
+	if strings.Join(seen, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v got %v", expected, seen)
+	}
+
+	// Array indexes count the elements in the source, even those dropped
+	seen = nil
+	dropSecrets := ParseOptions{Reviver: func(key string, v *Value) (*Value, error) {
+		seen = append(seen, key)
+		if v.StringOr("") == "secret" {
+			return nil, nil
+		}
+		return v, nil
+	}}
+	for _, parse := range []func(string) (*Value, error){
+		func(s string) (*Value, error) { return ParseWithOptions(strings.NewReader(s), dropSecrets) },
+		func(s string) (*Value, error) { return parseBytes([]byte(s), dropSecrets) },
+	} {
+		seen = nil
+		val, err := parse(`[1, "secret", 2, [3, "secret", "secret", 4], 5]`)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if expected := MustParse(`[1, 2, [3, 4], 5]`); !equals(expected, val) {
+			t.Errorf("expected %v got %v", expected, val)
+		}
+		if expected := "0,1,2,0,1,2,3,3,4,"; strings.Join(seen, ",") != expected {
+			t.Errorf("expected %v got %v", expected, strings.Join(seen, ","))
+		}
+	}
+
+	// Errors stop parsing and are returned as is
+	errStop := errors.New("stop")
+	calls := 0
+	options.Reviver = func(key string, v *Value) (*Value, error) {
+		calls++
+		if key == "b" {
+			return nil, errStop
+		}
+		return v, nil
+	}
+	for _, parse := range []func(string) (*Value, error){
+		func(s string) (*Value, error) { return ParseWithOptions(strings.NewReader(s), options) },
+		func(s string) (*Value, error) { return parseBytes([]byte(s), options) },
+	} {
+		calls = 0
+		val, err := parse(`[{"a": 1, "b": 2, "c": 3}, 4]`)
+		if err != errStop {
+			t.Errorf("expected %v got %v", errStop, err)
+		}
+		if !val.IsNull() || calls != 2 {
+			t.Errorf("expected %v got %v after %d calls", NewNull(), val, calls)
+		}
+	}
+
+	// The whole document can be replaced
+	options.Reviver = func(key string, v *Value) (*Value, error) {
+		if key == "" {
+			return nil, nil
+		}
+		return v, nil
+	}
+	if val, err := ParseWithOptions(strings.NewReader(`5`), options); err != nil || !val.IsNull() {
+		t.Errorf("expected %v got %v, %v", NewNull(), val, err)
+	}
+}
+
+func TestParseLenientNumbers(t *testing.T) {
+	options := DefaultParseOptions()
+	options.AllowLenientNumbers = true
+	for _, test := range []struct {
+		input    string
+		expected *Value
+		raw      string
+	}{
+		{`+5`, &Value{jsonType: Integer, integerValue: 5}, "5"},
+		{`+0`, &Value{jsonType: Integer, integerValue: 0}, "0"},
+		{`+1.5e3`, &Value{jsonType: Number, numberValue: 1500}, "1.5e3"},
+		{`.5`, &Value{jsonType: Number, numberValue: 0.5}, "0.5"},
+		{`-.5`, &Value{jsonType: Number, numberValue: -0.5}, "-0.5"},
+		{`+.5`, &Value{jsonType: Number, numberValue: 0.5}, "0.5"},
+		{`5.`, &Value{jsonType: Number, numberValue: 5}, "5.0"},
+		{`-5.`, &Value{jsonType: Number, numberValue: -5}, "-5.0"},
+		{`5.e2`, &Value{jsonType: Number, numberValue: 500}, "5.0e2"},
+		{`.5E1`, &Value{jsonType: Number, numberValue: 5}, "0.5E1"},
+		{`+123456789012345678901234567890`, &Value{jsonType: Integer, bigValue: bigIntFromString("123456789012345678901234567890")}, "123456789012345678901234567890"},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			actual, err := ParseWithOptions(strings.NewReader(test.input), options)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if raw, _ := actual.AsRawNumber(); raw != test.raw {
+				t.Errorf("expected %v got %v", test.raw, raw)
+			}
+			if _, err := ParseString(test.input); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+		})
+	}
+
+	val, err := ParseWithOptions(strings.NewReader(`[+1, .5, 5., {"a": 2.}, 3./**/, 4.
+	]`), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if b, _ := Marshal(val); string(b) != `[1,0.5,5.0,{"a":2.0},3.0,4.0]` {
+		t.Errorf("expected %v got %v", `[1,0.5,5.0,{"a":2.0},3.0,4.0]`, string(b))
+	}
+
+	for _, input := range []string{`+`, `.`, `-.`, `+.`, `++5`, `+-5`, `-+5`, `5..`, `.e5`, `5.x`, `+"a"`, `+1.5.`} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseWithOptions(strings.NewReader(input), options); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+		})
+	}
+
+	// A comment doesn't let a number end with . in strict mode
+	strict := ParseOptions{AllowComments: true}
+	for _, input := range []string{`5./**/`, `5.//x`, `[5./**/]`, `-5./**/`, `{"a": 5. /**/}`} {
+		t.Run("strict "+input, func(t *testing.T) {
+			if _, err := ParseWithOptions(strings.NewReader(input), strict); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if _, err := ParseBytes([]byte(input)); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if Valid([]byte(input)) || ValidString(input) {
+				t.Errorf("expected %v got %v", false, true)
+			}
+		})
+	}
+}
+
+func TestParseTrailingLineComment(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected *Value
+		err      error
+	}{
+		{`[1, 2] // comment`, &Value{jsonType: Array, arrayValue: []*Value{{jsonType: Integer, integerValue: 1}, {jsonType: Integer, integerValue: 2}}}, nil},
+		{`5// comment`, &Value{jsonType: Integer, integerValue: 5}, nil},
+		{`"a" // one
+		// two`, &Value{jsonType: String, stringValue: "a"}, nil},
+		{`true /* block */ // line`, &Value{jsonType: Boolean, booleanValue: true}, nil},
+		{`[1, // comment`, &Value{}, ErrUnexpectedEOF},
+		{`[1, 2 // comment`, &Value{}, ErrUnexpectedEOF},
+		{`{"a": // comment`, &Value{}, ErrUnexpectedEOF},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			actual, err := ParseString(test.input)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			if valid := Valid([]byte(test.input)); valid != (test.err == nil) {
+				t.Errorf("expected %v got %v", test.err == nil, valid)
+			}
+		})
+	}
+}
+
+func BenchmarkParseComments(b *testing.B) {
+	input := []byte("[\n")
+	for i := 0; i < 100; i++ {
+		input = append(input, "// a line comment\n/* a block comment */ 1,\n"...)
+	}
+	input = append(input, "2] // ends without a newline"...)
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		ParseBytes(input)
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	input := "// header\n{\n  \"a\": [1, 2.5e3, \"x\\n\"],\n  \"b\": {\"c\": true, \"d\": null} ,\n  \"e\": {}, \"f\": [ ], \"g\": -0\n}"
+	val, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, test := range []struct {
+		pointer  string
+		expected string
+		line     int
+		column   int
+	}{
+		{"", input[10:], 2, 1},
+		{"/a", `[1, 2.5e3, "x\n"]`, 3, 8},
+		{"/a/0", `1`, 3, 9},
+		{"/a/1", `2.5e3`, 3, 12},
+		{"/a/2", `"x\n"`, 3, 19},
+		{"/b", `{"c": true, "d": null}`, 4, 8},
+		{"/b/c", `true`, 4, 14},
+		{"/b/d", `null`, 4, 25},
+		{"/e", `{}`, 5, 8},
+		{"/f", `[ ]`, 5, 17},
+		{"/g", `-0`, 5, 27},
+	} {
+		t.Run(test.pointer, func(t *testing.T) {
+			v, err := val.Pointer(test.pointer)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			start, end := v.Span()
+			if actual := input[start:end]; actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			line, column := v.Position()
+			if line != test.line || column != test.column {
+				t.Errorf("expected %v:%v got %v:%v", test.line, test.column, line, column)
+			}
+		})
+	}
+
+	for _, input := range []string{`5`, `"s"`, `false`} {
+		t.Run(input, func(t *testing.T) {
+			start, end := MustParse(input).Span()
+			if start != 0 || end != len(input) {
+				t.Errorf("expected %v-%v got %v-%v", 0, len(input), start, end)
+			}
+		})
+	}
+
+	if start, end := NewInt(5).Span(); start != 0 || end != 0 {
+		t.Errorf("expected %v-%v got %v-%v", 0, 0, start, end)
+	}
+}
+
+// Makes a rune source that decodes UTF-16, with sizes in bytes.
+func utf16Runes(s string) func() (rune, int, error) {
+	units := utf16.Encode([]rune(s))
+	return func() (rune, int, error) {
+		if len(units) == 0 {
+			return 0, 0, io.EOF
+		}
+		if utf16.IsSurrogate(rune(units[0])) && len(units) > 1 {
+			r := utf16.DecodeRune(rune(units[0]), rune(units[1]))
+			units = units[2:]
+			return r, 4, nil
+		}
+		r := rune(units[0])
+		units = units[1:]
+		return r, 2, nil
+	}
+}
+
+// Makes a rune source that decodes Latin-1, where every byte is a rune.
+func latin1Runes(b []byte) func() (rune, int, error) {
+	return func() (rune, int, error) {
+		if len(b) == 0 {
+			return 0, 0, io.EOF
+		}
+		r := rune(b[0])
+		b = b[1:]
+		return r, 1, nil
+	}
+}
+
+func TestParseRunes(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		next     func() (rune, int, error)
+		expected string
+	}{
+		{"utf-16", utf16Runes(`{"a": ["😀", "é"], "b": 1.5} // done`), `{"a": ["😀", "é"], "b": 1.5}`},
+		{"latin-1", latin1Runes([]byte("[\"caf\xe9\", true]")), `["café", true]`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ParseRunes(test.next)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.expected); !equals(expected, actual) {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+		})
+	}
+
+	val, _ := ParseRunes(utf16Runes(`["😀", 5]`))
+	if start, end := val.Index(1).Span(); start != 14 || end != 16 {
+		t.Errorf("expected %v-%v got %v-%v", 14, 16, start, end)
+	}
+
+	_, err := ParseRunes(utf16Runes(`[1, x]`))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Offset != 8 {
+		t.Errorf("expected offset %v got %v", 8, err)
+	}
+
+	_, err = ParseRunes(func() (rune, int, error) { return 0, 0, io.ErrClosedPipe })
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected %v got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestParseIncomplete(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{`[`, "array"},
+		{`[1`, "array"},
+		{`[1, 2`, "array"},
+		{`[1, 2.5e3`, "array"},
+		{`[true`, "array"},
+		{`["a"`, "array"},
+		{`[[]`, "array"},
+		{`[1, 2 /* comment */`, "array"},
+		{`{`, "object"},
+		{`{"a"`, "object"},
+		{`{"a":`, "object"},
+		{`{"a": 1`, "object"},
+		{`{"a": null`, "object"},
+		{`{"a": [1, {"b": 2}]`, "object"},
+		{`{"a": [1, {"b": 2}`, "array"},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			for _, parse := range []func(string) (*Value, error){
+				ParseString,
+				func(s string) (*Value, error) { return ParseBytes([]byte(s)) },
+				func(s string) (*Value, error) { v, _, err := ParsePrefix([]byte(s)); return v, err },
+				func(s string) (*Value, error) { return NewStreamDecoder(strings.NewReader(s)).Next() },
+			} {
+				actual, err := parse(test.input)
+				if !errors.Is(err, ErrUnexpectedEOF) {
+					t.Errorf("expected %v got %v", ErrUnexpectedEOF, err)
+				}
+				if err != nil && !strings.Contains(err.Error(), "while parsing "+test.expected) {
+					t.Errorf("expected %v got %v", test.expected, err)
+				}
+				if !equals(&Value{}, actual) {
+					t.Errorf("expected %v got %v", &Value{}, actual)
+				}
+			}
+			if ValidString(test.input) {
+				t.Errorf("expected %v got %v", false, true)
+			}
+		})
+	}
+}
+
+func TestParseMaxBytes(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		maxBytes int
+		err      error
+	}{
+		{`[1, 2, 3]`, 0, nil},
+		{`[1, 2, 3]`, -1, nil},
+		{`[1, 2, 3]`, 9, nil},
+		{`[1, 2, 3]`, 8, ErrTooLarge},
+		{`"世界"`, 8, nil},
+		{`"世界"`, 7, ErrTooLarge},
+		{`[1, 2, 3]   `, 9, ErrTooLarge},
+		{strings.Repeat(" ", 100) + `1`, 50, ErrTooLarge},
+	} {
+		t.Run(fmt.Sprint(test.input, test.maxBytes), func(t *testing.T) {
+			options := DefaultParseOptions()
+			options.MaxBytes = test.maxBytes
+			_, err := ParseWithOptions(strings.NewReader(test.input), options)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			var parseErr *ParseError
+			if test.err != nil && (!errors.As(err, &parseErr) || parseErr.Offset != test.maxBytes) {
+				t.Errorf("expected offset %v got %v", test.maxBytes, err)
+			}
+			if _, err := NewParser(options).ParseBytes([]byte(test.input)); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestParseWithStats(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected ParseStats
+		err      error
+	}{
+		{`5`, ParseStats{Bytes: 1, Numbers: 1}, nil},
+		{`"a" `, ParseStats{Bytes: 4, Strings: 1}, nil},
+		{`[]`, ParseStats{Bytes: 2, Arrays: 1, MaxDepth: 1}, nil},
+		{`{"a": [1, 2.5, {"b": "c"}], "d": [[null, true]] /* [ */}`, ParseStats{Bytes: 56, Objects: 2, Arrays: 3, Strings: 1, Numbers: 2, MaxDepth: 3}, nil},
+		{`[1, 2, x]`, ParseStats{Bytes: 7, Arrays: 1, Numbers: 2, MaxDepth: 1}, ErrParse},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val, stats, err := ParseWithStats(strings.NewReader(test.input), DefaultParseOptions())
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if stats != test.expected {
+				t.Errorf("expected %+v got %+v", test.expected, stats)
+			}
+			if err == nil && stats.MaxDepth != val.MaxDepth() {
+				t.Errorf("expected %v got %v", val.MaxDepth(), stats.MaxDepth)
+			}
+		})
+	}
+}
+
+func TestParseModeMismatch(t *testing.T) {
+	// These states can't be reached with the wrong mode on the stack from
+	// any input, so set them up directly.
+	for _, test := range []struct {
+		state state
+		mode  mode
+		input rune
+	}{
+		{ob, modeArray, '}'},
+		{ke, modeArray, '}'},
+		{ar, modeKey, ']'},
+		{tc, modeObject, ']'},
+		{co, modeArray, ':'},
+	} {
+		t.Run(string(test.input), func(t *testing.T) {
+			p := newParser(DefaultParseOptions())
+			p.pushMode(test.mode)
+			p.state = test.state
+			err := p.consumeCharacter(test.input)
+			if !errors.Is(err, ErrUnmatchedBrace) {
+				t.Errorf("expected %v got %v", ErrUnmatchedBrace, err)
+			}
+			if p.isRunning {
+				t.Errorf("expected %v got %v", false, true)
+			}
+		})
+	}
+}
+
+func TestParseIncompleteUTF8(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		offset int
+	}{
+		{"\"\xe4\xb8", 1},
+		{"[\"a\", \"\xf0\x9f\x98", 7},
+		{"\xe4", 0},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			for _, parse := range []func(string) (*Value, error){
+				ParseString,
+				func(s string) (*Value, error) { return ParseBytes([]byte(s)) },
+				func(s string) (*Value, error) { return Parse(iotest.OneByteReader(strings.NewReader(s))) },
+				func(s string) (*Value, error) { return NewStreamDecoder(strings.NewReader(s)).Next() },
+			} {
+				_, err := parse(test.input)
+				if !errors.Is(err, ErrInvalidUTF8) {
+					t.Errorf("expected %v got %v", ErrInvalidUTF8, err)
+				}
+				expected := fmt.Sprintf("incomplete UTF-8 sequence at line 1, column %d (byte %d)", test.offset+1, test.offset)
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || parseErr.Offset != test.offset || !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected %v got %v", expected, err)
+				}
+			}
+		})
+	}
+
+	// A bad byte in the middle is invalid rather than incomplete
+	if _, err := ParseString("[\"\xe4\xb8\"]"); err == nil || strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected %v got %v", "invalid UTF-8 character", err)
+	}
+
+	// Splitting characters across reads doesn't matter
+	val, err := Parse(iotest.OneByteReader(strings.NewReader(`["世界", "😀"]`)))
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if expected := MustParse(`["世界", "😀"]`); !equals(expected, val) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+}
+
+// Reads from a reader only through Read and ReadByte.
+type byteOnlyReader struct{ r *strings.Reader }
+
+func (b byteOnlyReader) Read(p []byte) (int, error) { return b.r.Read(p) }
+func (b byteOnlyReader) ReadByte() (byte, error)    { return b.r.ReadByte() }
+
+// Reads from a reader only through Read and ReadRune.
+type runeOnlyReader struct{ r *strings.Reader }
+
+func (b runeOnlyReader) Read(p []byte) (int, error)   { return b.r.Read(p) }
+func (b runeOnlyReader) ReadRune() (rune, int, error) { return b.r.ReadRune() }
+
+func TestParseReaderKinds(t *testing.T) {
+	for _, input := range []string{
+		`{"a": [1, 2.5, "世界"], "b": "😀"}`,
+		"\ufeff[true]",
+		`[1, 2`,
+		"[\"a\xff\"]",
+		"[\"\xe4\xb8\"]",
+		"\"\xe4\xb8",
+		"",
+	} {
+		t.Run(input, func(t *testing.T) {
+			expected, expectedErr := Parse(iotest.OneByteReader(strings.NewReader(input)))
+			for name, r := range map[string]io.Reader{
+				"ByteReader":     byteOnlyReader{strings.NewReader(input)},
+				"RuneReader":     runeOnlyReader{strings.NewReader(input)},
+				"strings.Reader": strings.NewReader(input),
+				"bufio.Reader":   bufio.NewReader(strings.NewReader(input)),
+			} {
+				val, err := Parse(r)
+				if !equals(expected, val) {
+					t.Errorf("%s: expected %v got %v", name, expected, val)
+				}
+				if (err == nil) != (expectedErr == nil) {
+					t.Errorf("%s: expected %v got %v", name, expectedErr, err)
+				}
+				// A plain RuneReader can't tell if a character was cut off
+				if err != nil && name != "RuneReader" && err.Error() != expectedErr.Error() {
+					t.Errorf("%s: expected %v got %v", name, expectedErr, err)
+				}
+			}
+
+			val, err := NewParser(DefaultParseOptions()).Parse(byteOnlyReader{strings.NewReader(input)})
+			if !equals(expected, val) || (err == nil) != (expectedErr == nil) || (err != nil && err.Error() != expectedErr.Error()) {
+				t.Errorf("expected %v, %v got %v, %v", expected, expectedErr, val, err)
+			}
+		})
+	}
+}
+
+func TestParseReplacementCharacter(t *testing.T) {
+	input := "[\"a\ufffdb\", \"\\ufffd\"]"
+	expected := NewArray(NewString("a\ufffdb"), NewString("\ufffd"))
+	for _, parse := range []func(string) (*Value, error){
+		ParseString,
+		func(s string) (*Value, error) { return ParseBytes([]byte(s)) },
+	} {
+		actual, err := parse(input)
+		if err != nil {
+			t.Errorf("expected no error got %v", err)
+		}
+		if !equals(expected, actual) {
+			t.Errorf("expected %v got %v", expected, actual)
+		}
+	}
+}
+
+func TestParseInternStrings(t *testing.T) {
+	input := `[{"name": "a", "kind": "x"}, {"name": "b", "kind": "x"}, {"kind": "name"}]`
+	data := func(s string) uintptr {
+		return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+	}
+
+	options := DefaultParseOptions()
+	options.InternStrings = true
+	val, err := ParseWithOptions(strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if expected := MustParse(input); !equals(expected, val) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+	records := val.arrayValue
+	if expected, actual := data(records[0].objectValue[0].key), data(records[1].objectValue[0].key); expected != actual {
+		t.Errorf("expected keys to share memory")
+	}
+	if expected, actual := data(records[0].objectValue[1].val.stringValue), data(records[1].objectValue[1].val.stringValue); expected != actual {
+		t.Errorf("expected values to share memory")
+	}
+	if expected, actual := data(records[0].objectValue[0].key), data(records[2].objectValue[0].val.stringValue); expected != actual {
+		t.Errorf("expected keys and values to share memory")
+	}
+
+	// Without the option every string has its own copy
+	val = MustParse(input)
+	records = val.arrayValue
+	if data(records[0].objectValue[0].key) == data(records[1].objectValue[0].key) {
+		t.Errorf("expected keys not to share memory")
+	}
+
+	// The interned strings are forgotten on reset
+	p := NewParser(options)
+	if _, err := p.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	p.Reset()
+	if len(p.pda.interned) != 0 {
+		t.Errorf("expected 0 interned strings got %v", len(p.pda.interned))
+	}
+}
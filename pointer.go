@@ -0,0 +1,280 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Resolves an RFC 6901 JSON Pointer such as "/members/2/name" against the value.
+// The empty pointer "" refers to the value itself.
+//
+// Returns ErrPointer if the pointer is malformed, ErrNotFound if an object has
+// no member with the referenced key or an array token isn't a valid index,
+// ErrRange if an array index is out of bounds, and ErrType if the pointer
+// tries to descend into a value that is neither an array nor an object.
+func (v *Value) Pointer(p string) (*Value, error) {
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return &Value{}, err
+	}
+
+	cur := v
+	for i, token := range tokens {
+		switch cur.jsonType {
+		case Object:
+			next, ok := cur.lookup(token)
+			if !ok {
+				return &Value{}, fmt.Errorf("%w: key %q at %q", ErrNotFound, token, formatPointer(tokens[:i+1]))
+			}
+			cur = next
+		case Array:
+			idx, err := parseIndex(token)
+			if err != nil {
+				return &Value{}, fmt.Errorf("%w: invalid array index %q at %q", ErrNotFound, token, formatPointer(tokens[:i+1]))
+			}
+			if idx >= len(cur.arrayValue) {
+				return &Value{}, fmt.Errorf("%w: index %s with length %d at %q", ErrRange, token, len(cur.arrayValue), formatPointer(tokens[:i+1]))
+			}
+			cur = cur.arrayValue[idx]
+		default:
+			return &Value{}, fmt.Errorf("%w: cannot descend into %v at %q", ErrType, cur.jsonType, formatPointer(tokens[:i]))
+		}
+	}
+	return cur, nil
+}
+
+// Sets the value at an RFC 6901 JSON Pointer, creating any arrays and objects
+// along the way that don't exist yet, like mkdir -p. A missing member is
+// created as an array if the token after it is an array index or "-", and as
+// an object otherwise, and nulls along the way are replaced the same way.
+// The "-" token appends to an array, and so does the index of its length.
+// Setting an index further past the end extends the array with nulls, up to
+// 1024 of them, so that a pointer such as /999999999999 can't use up memory.
+//
+// Returns ErrPointer if the pointer is malformed or is "", since the value
+// can't replace itself, ErrNotFound if an array token isn't a valid index,
+// ErrRange if an index is too far past the end of an array, and ErrType if
+// the pointer tries to descend into a value that is neither an array nor an
+// object, null aside.
+// If an error is returned, the value is left unchanged.
+func (v *Value) SetPointer(p string, val *Value) error {
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("%w: cannot set the value itself", ErrPointer)
+	}
+	return v.setPath(tokens, val)
+}
+
+// Sets the value at the reference tokens, of which there must be at least
+// one, creating containers along the way. See SetPointer.
+func (v *Value) setPath(tokens []string, val *Value) error {
+	// Find how much of the path already exists
+	cur, i := v, 0
+	for ; i < len(tokens)-1; i++ {
+		next := cur.child(tokens[i])
+		if next == nil || next.jsonType == Null {
+			break
+		}
+		cur = next
+	}
+
+	// Build the rest of the path from the bottom up, so that nothing is
+	// changed unless it all succeeds
+	for j := len(tokens) - 1; j > i; j-- {
+		c := newContainer(tokens[j])
+		if err := c.put(tokens[:j+1], val); err != nil {
+			return err
+		}
+		val = c
+	}
+	return cur.put(tokens[:i+1], val)
+}
+
+// How many nulls setting an array index past the end may add before it.
+const maxArrayGap = 1024
+
+// Gets the existing member of an array or object that a reference token
+// refers to, or nil if there isn't one.
+func (v *Value) child(token string) *Value {
+	switch v.jsonType {
+	case Object:
+		next, _ := v.lookup(token)
+		return next
+	case Array:
+		if idx, err := parseIndex(token); err == nil && idx < len(v.arrayValue) {
+			return v.arrayValue[idx]
+		}
+	}
+	return nil
+}
+
+// Makes an empty array if a reference token can index one, or an empty
+// object otherwise.
+func newContainer(token string) *Value {
+	if _, err := parseIndex(token); err == nil {
+		return NewArray()
+	}
+	return NewObject()
+}
+
+// Sets the member of an array or object that the last of the reference
+// tokens refers to, extending an array with nulls if it is a little too short.
+func (v *Value) put(tokens []string, val *Value) error {
+	token := tokens[len(tokens)-1]
+	switch v.jsonType {
+	case Object:
+		return v.Set(token, val)
+	case Array:
+		idx := len(v.arrayValue)
+		if token != "-" {
+			var err error
+			if idx, err = parseIndex(token); err != nil {
+				return fmt.Errorf("%w: invalid array index %q at %q", ErrNotFound, token, formatPointer(tokens))
+			}
+			if idx-len(v.arrayValue) > maxArrayGap {
+				return fmt.Errorf("%w: index %s is too far past the end of array with length %d at %q", ErrRange, token, len(v.arrayValue), formatPointer(tokens))
+			}
+		}
+		for len(v.arrayValue) <= idx {
+			v.arrayValue = append(v.arrayValue, NewNull())
+		}
+		v.arrayValue[idx] = val
+		return nil
+	}
+	return fmt.Errorf("%w: cannot descend into %v at %q", ErrType, v.jsonType, formatPointer(tokens[:len(tokens)-1]))
+}
+
+// Splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("%w: %q does not start with '/'", ErrPointer, p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("%w: invalid escape in %q", ErrPointer, p)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Walks the whole value and gets every leaf keyed by its JSON Pointer, such as
+// "/members/0/name". Leaves are scalars and empty arrays and objects, so that
+// no part of the value is left out. A scalar at the root is keyed by "".
+// If an object has duplicate keys, only the first one is included, since
+// that's the one its pointer refers to.
+func (v *Value) Paths() map[string]*Value {
+	paths := map[string]*Value{}
+	v.paths(paths, "")
+	return paths
+}
+
+// Adds the leaves under a value to paths, where path is the value's pointer.
+func (v *Value) paths(paths map[string]*Value, path string) {
+	switch {
+	case v.jsonType == Array && len(v.arrayValue) > 0:
+		for i, val := range v.arrayValue {
+			val.paths(paths, path+"/"+strconv.Itoa(i))
+		}
+	case v.jsonType == Object && len(v.objectValue) > 0:
+		seen := map[string]bool{}
+		for _, p := range v.objectValue {
+			if seen[p.key] {
+				continue
+			}
+			seen[p.key] = true
+			p.val.paths(paths, path+formatPointer([]string{p.key}))
+		}
+	default:
+		paths[path] = v
+	}
+}
+
+// What Redact puts in place of the values it hides.
+const redacted = "***"
+
+// Makes a copy of the value with the values at the given JSON Pointers
+// replaced by the string "***", such as to hide passwords and tokens before
+// logging a document. The original is left unchanged. If an object has
+// duplicate keys, every member with the key is replaced, so that the hidden
+// value can't be read through AsObject either. Pointers that are malformed
+// or don't refer to anything in the value are ignored.
+func (v *Value) Redact(paths ...string) *Value {
+	c := v.Clone()
+	for _, p := range paths {
+		tokens, err := parsePointer(p)
+		if err != nil {
+			continue
+		}
+		c.redact(tokens)
+	}
+	return c
+}
+
+// Replaces the values the reference tokens refer to under a value.
+func (v *Value) redact(tokens []string) {
+	if len(tokens) == 0 {
+		*v = Value{jsonType: String, stringValue: redacted}
+		return
+	}
+	switch v.jsonType {
+	case Object:
+		for _, p := range v.objectValue {
+			if p.key == tokens[0] {
+				p.val.redact(tokens[1:])
+			}
+		}
+	case Array:
+		if i, err := parseIndex(tokens[0]); err == nil && i < len(v.arrayValue) {
+			v.arrayValue[i].redact(tokens[1:])
+		}
+	}
+}
+
+// Joins reference tokens back into an escaped JSON Pointer.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// Parses an array index token. Per RFC 6901 it must be a base-10 number
+// without leading zeros. The "-" token refers to the (nonexistent) member
+// after the last one, so it is reported as past the end of any array.
+func parseIndex(token string) (int, error) {
+	if token == "-" {
+		return math.MaxInt, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, strconv.ErrSyntax
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		// too large to be an index of anything
+		return math.MaxInt, nil
+	}
+	return idx, nil
+}
@@ -0,0 +1,224 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPointer(t *testing.T) {
+	// Example document from RFC 6901 section 5
+	val, err := ParseString(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`)
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	for _, test := range []struct {
+		pointer  string
+		expected *Value
+	}{
+		{"", val},
+		{"/foo", val.Key("foo")},
+		{"/foo/0", NewString("bar")},
+		{"/foo/1", NewString("baz")},
+		{"/", NewInt(0)},
+		{"/a~1b", NewInt(1)},
+		{"/c%d", NewInt(2)},
+		{"/e^f", NewInt(3)},
+		{"/g|h", NewInt(4)},
+		{"/i\\j", NewInt(5)},
+		{"/k\"l", NewInt(6)},
+		{"/ ", NewInt(7)},
+		{"/m~0n", NewInt(8)},
+	} {
+		t.Run(test.pointer, func(t *testing.T) {
+			actual, err := val.Pointer(test.pointer)
+			if err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPointerInvalid(t *testing.T) {
+	val, _ := ParseString(`{"a": [0, {"b": null}], "c": true}`)
+	for _, test := range []struct {
+		pointer  string
+		expected error
+	}{
+		{"a", ErrPointer},
+		{"/a~", ErrPointer},
+		{"/a~2", ErrPointer},
+		{"/b", ErrNotFound},
+		{"/a/1/c", ErrNotFound},
+		{"/a/x", ErrNotFound},
+		{"/a/01", ErrNotFound},
+		{"/a/", ErrNotFound},
+		{"/a/-1", ErrNotFound},
+		{"/a/2", ErrRange},
+		{"/a/-", ErrRange},
+		{"/a/99999999999999999999", ErrRange},
+		{"/c/d", ErrType},
+		{"/a/0/0", ErrType},
+	} {
+		t.Run(test.pointer, func(t *testing.T) {
+			actual, err := val.Pointer(test.pointer)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+			if actual.Type() != Null {
+				t.Errorf("expected %v got %v", Null, actual.Type())
+			}
+		})
+	}
+}
+
+func TestPaths(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected map[string]string
+	}{
+		{`5`, map[string]string{"": "5"}},
+		{`[]`, map[string]string{"": "[]"}},
+		{`{"members": [{"name": "a", "tags": []}, {"name": "b", "meta": {}}], "count": 2}`, map[string]string{
+			"/members/0/name": `"a"`,
+			"/members/0/tags": "[]",
+			"/members/1/name": `"b"`,
+			"/members/1/meta": "{}",
+			"/count":          "2",
+		}},
+		{`{"a/b": {"m~n": null}, "": true}`, map[string]string{
+			"/a~1b/m~0n": "null",
+			"/":          "true",
+		}},
+		{`{"a": 1, "a": 2}`, map[string]string{"/a": "1"}},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			actual := val.Paths()
+			if len(actual) != len(test.expected) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			for path, expected := range test.expected {
+				leaf, ok := actual[path]
+				if !ok {
+					t.Errorf("expected %v got none", path)
+					continue
+				}
+				if leaf.String() != expected {
+					t.Errorf("expected %v got %v", expected, leaf)
+				}
+				if p, _ := val.Pointer(path); p != leaf {
+					t.Errorf("expected %v got %v", leaf, p)
+				}
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		paths    []string
+		expected string
+	}{
+		{`{"user": "a", "password": "hunter2"}`, []string{"/password"}, `{"user": "a", "password": "***"}`},
+		{`{"auth": {"token": "t", "scopes": ["x"]}, "keys": ["k1", "k2"]}`, []string{"/auth", "/keys/1"}, `{"auth": "***", "keys": ["k1", "***"]}`},
+		{`{"a": 1, "a": 2}`, []string{"/a"}, `{"a": "***", "a": "***"}`},
+		{`{"a~b": {"c/d": 1}}`, []string{"/a~0b/c~1d"}, `{"a~b": {"c/d": "***"}}`},
+		{`{"a": [1]}`, []string{"/b", "/a/1", "/a/-", "/a/x", "/a/0/deeper", "bad"}, `{"a": [1]}`},
+		{`{"a": 1}`, []string{""}, `"***"`},
+		{`{"a": 1}`, nil, `{"a": 1}`},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			original := val.Clone()
+			actual := val.Redact(test.paths...)
+			if expected := MustParse(test.expected); !equals(expected, actual) {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if !equals(original, val) {
+				t.Errorf("expected %v got %v", original, val)
+			}
+		})
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		pointer  string
+		expected string
+	}{
+		{`{}`, "/a", `{"a": 1}`},
+		{`{"a": 0, "b": 2}`, "/a", `{"a": 1, "b": 2}`},
+		{`{}`, "/a/b/c", `{"a": {"b": {"c": 1}}}`},
+		{`{}`, "/a/0", `{"a": [1]}`},
+		{`{}`, "/a/2/b", `{"a": [null, null, {"b": 1}]}`},
+		{`{}`, "/a/-", `{"a": [1]}`},
+		{`{"a": [5]}`, "/a/-", `{"a": [5, 1]}`},
+		{`{"a": [5]}`, "/a/0", `{"a": [1]}`},
+		{`{"a": [5]}`, "/a/3", `{"a": [5, null, null, 1]}`},
+		{`{"a": [5]}`, "/a/1", `{"a": [5, 1]}`},
+		{`[]`, "/1024", "[" + strings.Repeat("null, ", 1024) + "1]"},
+		{`{"a": [null]}`, "/a/0/b", `{"a": [{"b": 1}]}`},
+		{`{"a": null}`, "/a/x", `{"a": {"x": 1}}`},
+		{`{"a": {"b": 2}}`, "/a/c", `{"a": {"b": 2, "c": 1}}`},
+		{`[]`, "/0/01", `[{"01": 1}]`},
+		{`{}`, "/m~0n/a~1b", `{"m~n": {"a/b": 1}}`},
+		{`{"a": 1, "a": 2}`, "/a", `{"a": 1}`},
+	} {
+		t.Run(test.input+test.pointer, func(t *testing.T) {
+			val := MustParse(test.input)
+			if err := val.SetPointer(test.pointer, NewInt(1)); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if expected := MustParse(test.expected); val.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, val)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		input   string
+		pointer string
+		err     error
+	}{
+		{`{}`, "", ErrPointer},
+		{`{}`, "a", ErrPointer},
+		{`{}`, "/a~2", ErrPointer},
+		{`[]`, "/x", ErrNotFound},
+		{`{"a": [[]]}`, "/a/0/x/y", ErrNotFound},
+		{`[]`, "/99999999999999999999", ErrRange},
+		{`{}`, "/a/99999999999999999999/b", ErrRange},
+		{`{}`, "/a/999999999999", ErrRange},
+		{`[]`, "/1025", ErrRange},
+		{`[1, 2]`, "/1027", ErrRange},
+		{`{"a": 5}`, "/a/b", ErrType},
+		{`{"a": "s"}`, "/a/0/b", ErrType},
+		{`5`, "/a", ErrType},
+	} {
+		t.Run(test.input+test.pointer, func(t *testing.T) {
+			val := MustParse(test.input)
+			if err := val.SetPointer(test.pointer, NewInt(1)); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if expected := MustParse(test.input); val.String() != expected.String() {
+				t.Errorf("expected %v got %v", expected, val)
+			}
+		})
+	}
+}
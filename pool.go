@@ -0,0 +1,166 @@
+package json
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// A Parser parses JSON values one after another, reusing the memory
+// allocated for its stacks and buffers instead of allocating new ones for
+// every document, so that once it has parsed a document, the only memory
+// parsing another one like it allocates is for the values it returns.
+// ParseInto reuses those as well. It is safe to keep Parsers in a
+// sync.Pool, but a single Parser must not be used from more than one
+// goroutine at a time.
+//
+// The zero value is ready to use and parses strictly valid JSON, the same
+// as the zero value of ParseOptions.
+type Parser struct {
+	pda     parser
+	reader  *bufio.Reader
+	options ParseOptions
+
+	// state of a value being fed in chunks
+	feeding bool
+	partial []byte // the start of a character split across chunks
+	err     error
+}
+
+// Creates a parser that accepts the extensions enabled in the options.
+func NewParser(options ParseOptions) *Parser {
+	return &Parser{options: options}
+}
+
+// Lets go of anything left over from the last document parsed, so a
+// parser put back into a pool doesn't hold on to its values or reader.
+func (p *Parser) Reset() {
+	p.pda.reset(p.options)
+	if p.reader != nil {
+		p.reader.Reset(nil)
+	}
+	p.feeding = false
+	p.partial = p.partial[:0]
+	p.err = nil
+}
+
+// Parses a JSON value from a Reader. If it cannot read a valid value,
+// it returns a null value and a non-nil error.
+// Returns the parsed value and nil error otherwise.
+func (p *Parser) Parse(r io.Reader) (*Value, error) {
+	defer p.Reset()
+	p.pda.reset(p.options)
+	return p.parse(r)
+}
+
+// Parses a JSON value from a Reader into v, replacing what was in it. The
+// values that made up v's old contents are reused to build the new ones
+// instead of allocating more, which saves work when parsing many documents
+// of a similar shape one after another. Since they are overwritten, nothing
+// that was in v, such as a member taken out with Key or Index, may be used
+// afterwards. If it cannot read a valid value, v is set to null and it
+// returns a non-nil error.
+func (p *Parser) ParseInto(v *Value, r io.Reader) error {
+	defer p.Reset()
+	p.pda.reset(p.options)
+	p.pda.recycling = true
+	p.pda.recycle(v)
+	val, err := p.parse(r)
+	*v = *val
+	return err
+}
+
+// Runs the parser over a reader, buffering it if needed.
+func (p *Parser) parse(r io.Reader) (*Value, error) {
+	if rr, ok := unbuffered(r); ok {
+		return p.pda.parse(rr)
+	}
+	if p.reader == nil {
+		p.reader = bufio.NewReader(r)
+	} else {
+		p.reader.Reset(r)
+	}
+	return p.pda.parse(p.reader)
+}
+
+// Parses a JSON value from a byte slice. If it cannot read a valid value,
+// it returns a null value and a non-nil error.
+// Returns the parsed value and nil error otherwise.
+func (p *Parser) ParseBytes(b []byte) (*Value, error) {
+	defer p.Reset()
+	p.pda.reset(p.options)
+	return p.pda.parseBytes(b)
+}
+
+// Parses the next chunk of a JSON value that arrives a piece at a time, such
+// as from a non-blocking socket. Chunks may split the input anywhere, even in
+// the middle of a character. Call Finish after the last chunk to get the
+// value. Returns an error as soon as the input so far can't be the start of
+// a valid value, and every later call returns the same error until Finish
+// or Reset is called.
+func (p *Parser) Feed(b []byte) error {
+	if !p.feeding {
+		p.pda.reset(p.options)
+		p.feeding = true
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	// Finish off a character cut off by the last chunk
+	if len(p.partial) > 0 {
+		for len(b) > 0 && !utf8.FullRune(p.partial) {
+			p.partial = append(p.partial, b[0])
+			b = b[1:]
+		}
+		if !utf8.FullRune(p.partial) {
+			return nil
+		}
+		r, n := utf8.DecodeRune(p.partial)
+		p.partial = p.partial[:0]
+		if err := p.pda.step(r, n); err != nil {
+			p.err = err
+			return err
+		}
+	}
+
+	for i := 0; i < len(b); {
+		r, n := rune(b[i]), 1
+		if b[i] >= utf8.RuneSelf {
+			if !utf8.FullRune(b[i:]) {
+				p.partial = append(p.partial, b[i:]...)
+				return nil
+			}
+			r, n = utf8.DecodeRune(b[i:])
+		}
+		if err := p.pda.step(r, n); err != nil {
+			p.err = err
+			return err
+		}
+		i += n
+	}
+	return nil
+}
+
+// Ends the input given to Feed and gets the value it held. If the chunks
+// didn't make up a valid value, it returns a null value and a non-nil error.
+// Returns the parsed value and nil error otherwise. Either way, the parser
+// is then ready to be fed another value.
+func (p *Parser) Finish() (*Value, error) {
+	defer p.Reset()
+	if !p.feeding {
+		p.pda.reset(p.options)
+	}
+	if p.err != nil {
+		return &Value{}, p.err
+	}
+	if len(p.partial) > 0 {
+		return &Value{}, p.pda.errorf(ErrInvalidUTF8, "incomplete UTF-8 sequence")
+	}
+	p.pda.isEOF = true
+	p.pda.isRunning = false
+	if err := p.pda.step(0, 0); err != nil {
+		return &Value{}, err
+	}
+	return p.pda.valueStack[0], nil
+}
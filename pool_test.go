@@ -0,0 +1,242 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParserReuse(t *testing.T) {
+	p := NewParser(DefaultParseOptions())
+	for _, test := range []struct {
+		input    string
+		expected *Value
+		err      error
+	}{
+		{`[1, 2, [3]]`, &Value{jsonType: Array, arrayValue: []*Value{
+			{jsonType: Integer, integerValue: 1},
+			{jsonType: Integer, integerValue: 2},
+			{jsonType: Array, arrayValue: []*Value{{jsonType: Integer, integerValue: 3}}},
+		}}, nil},
+		{`{"a": "b" // comment
+		}`, &Value{jsonType: Object, objectValue: []pair{{"a", &Value{jsonType: String, stringValue: "b"}}}}, nil},
+		{`[1, x]`, &Value{}, ErrParse},
+		{`"abc"`, &Value{jsonType: String, stringValue: "abc"}, nil},
+		{`tru`, &Value{}, ErrParse},
+		{`12.5`, &Value{jsonType: Number, numberValue: 12.5}, nil},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			actual, err := p.Parse(strings.NewReader(test.input))
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+			actual, err = p.ParseBytes([]byte(test.input))
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if !equals(test.expected, actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParserZeroValue(t *testing.T) {
+	var p Parser
+	if _, err := p.ParseBytes([]byte(`[1, 2,]`)); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	v, err := p.ParseBytes([]byte(`[1, 2]`))
+	if err != nil {
+		t.Errorf("expected %v got %v", nil, err)
+	}
+	if s := v.String(); s != "[1, 2]" {
+		t.Errorf("expected %v got %v", "[1, 2]", s)
+	}
+}
+
+func TestParserErrorPosition(t *testing.T) {
+	p := NewParser(ParseOptions{})
+	p.ParseBytes([]byte("[\n\n1, 2, x]"))
+	_, err := p.ParseBytes([]byte("[1, x]"))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected %v got %v", ErrParse, err)
+	}
+	if parseErr.Line != 1 || parseErr.Column != 5 || parseErr.Offset != 4 {
+		t.Errorf("expected %v got %v", "line 1, column 5 (byte 4)", parseErr)
+	}
+}
+
+func TestParserPool(t *testing.T) {
+	pool := sync.Pool{New: func() any { return NewParser(DefaultParseOptions()) }}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := pool.Get().(*Parser)
+				v, err := p.ParseBytes(benchmarkInput)
+				pool.Put(p)
+				if err != nil {
+					t.Errorf("expected %v got %v", nil, err)
+					return
+				}
+				if !v.Has("object") {
+					t.Errorf("expected %v got %v", true, false)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParserFeed(t *testing.T) {
+	p := NewParser(DefaultParseOptions())
+	for _, input := range []string{
+		`{"a": [1, 2.5, "世界"], "b": "😀"}`,
+		`12.5`,
+		`"abc" // comment`,
+		`[1, 2`,
+		`[1, x]`,
+		"\"\xe4\xb8",
+		"[\"\xe4\xb8\"]",
+		``,
+	} {
+		t.Run(input, func(t *testing.T) {
+			expected, expectedErr := ParseBytes([]byte(input))
+			for size := 1; size <= len(input)+1; size++ {
+				var err error
+				for i := 0; i < len(input) && err == nil; i += size {
+					end := i + size
+					if end > len(input) {
+						end = len(input)
+					}
+					err = p.Feed([]byte(input[i:end]))
+				}
+				val, finishErr := p.Finish()
+				if err != nil && err != finishErr {
+					t.Errorf("chunks of %d: expected %v got %v", size, err, finishErr)
+				}
+				if !equals(expected, val) {
+					t.Errorf("chunks of %d: expected %v got %v", size, expected, val)
+				}
+				if (finishErr == nil) != (expectedErr == nil) || (finishErr != nil && finishErr.Error() != expectedErr.Error()) {
+					t.Errorf("chunks of %d: expected %v got %v", size, expectedErr, finishErr)
+				}
+			}
+		})
+	}
+
+	// Errors are reported as soon as they're seen and stick until Finish
+	if err := p.Feed([]byte(`[1, x`)); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	if err := p.Feed([]byte(`]`)); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	if _, err := p.Finish(); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	if err := p.Feed([]byte(`true`)); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if val, err := p.Finish(); err != nil || !equals(NewBool(true), val) {
+		t.Errorf("expected %v got %v, %v", NewBool(true), val, err)
+	}
+}
+
+func TestParserParseInto(t *testing.T) {
+	p := NewParser(DefaultParseOptions())
+	v := NewNull()
+	for _, input := range []string{
+		`{"a": [1, 2.5, "x"], "b": {"c": true, "d": null}}`,
+		`{"a": [3, 4.5, "y", "z"], "b": {"c": false}}`,
+		`[1, [2, [3]], {"a": "b"}]`,
+		`"abc"`,
+		`{"a": [1, 2.5, "x"], "b": {"c": true, "d": null}}`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			if err := p.ParseInto(v, strings.NewReader(input)); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if expected := MustParse(input); !equals(expected, v) {
+				t.Errorf("expected %v got %v", expected, v)
+			}
+		})
+	}
+
+	// A value that is in the tree twice is only reused once
+	shared := NewArray(NewInt(1))
+	v = NewArray(shared, shared, NewObject().SetKey("a", shared))
+	if err := p.ParseInto(v, strings.NewReader(`[[1], [2], [3], [4], {"a": [5]}]`)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if expected := MustParse(`[[1], [2], [3], [4], {"a": [5]}]`); !equals(expected, v) {
+		t.Errorf("expected %v got %v", expected, v)
+	}
+
+	if err := p.ParseInto(v, strings.NewReader(`[1, x]`)); !errors.Is(err, ErrParse) {
+		t.Errorf("expected %v got %v", ErrParse, err)
+	}
+	if !v.IsNull() {
+		t.Errorf("expected %v got %v", NewNull(), v)
+	}
+}
+
+func BenchmarkParserParseInto(b *testing.B) {
+	p := NewParser(DefaultParseOptions())
+	v := NewNull()
+	r := bytes.NewReader(benchmarkInput)
+	b.SetBytes(int64(len(benchmarkInput)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(benchmarkInput)
+		p.ParseInto(v, r)
+	}
+}
+
+func BenchmarkParserReuse(b *testing.B) {
+	p := NewParser(DefaultParseOptions())
+	b.SetBytes(int64(len(benchmarkInput)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.ParseBytes(benchmarkInput)
+	}
+}
+
+func TestParserReuseAllocs(t *testing.T) {
+	long := []byte(`"` + strings.Repeat("a", 10000) + `"`)
+	deep := []byte(strings.Repeat("[", 500) + strings.Repeat("]", 500))
+	for _, test := range []struct {
+		name  string
+		input []byte
+		max   float64
+	}{
+		// About the value and its string
+		{"long", long, 4},
+		// About each array's value and the slice holding the next one
+		{"deep", deep, 1000},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewParser(DefaultParseOptions())
+			if _, err := p.ParseBytes(test.input); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			actual := testing.AllocsPerRun(10, func() { p.ParseBytes(test.input) })
+			if actual > test.max {
+				t.Errorf("expected at most %v got %v", test.max, actual)
+			}
+			if fresh := testing.AllocsPerRun(10, func() { ParseBytes(test.input) }); fresh <= actual {
+				t.Errorf("expected more than %v got %v", actual, fresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+)
+
+// One step of a query: an object key, an array index, or a wildcard.
+type queryStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Finds the values matching a path expression, a lighter-weight alternative
+// to JSON Pointer and JSONPath for picking values out of a document, such as
+// in configuration. An expression is a series of steps: a key such as name,
+// which comes first or after a dot, as in a.b.c, an array index in brackets
+// such as items[0], or a wildcard, [*] or .*, which matches every element of
+// an array or every member of an object. So members[*].name gets the name of
+// every member. Keys containing dots or brackets can be written in brackets
+// as a quoted JSON string, as in ["a.b"]. The empty expression matches the
+// value itself.
+//
+// Steps that don't match anything, such as a missing key or an index past the
+// end of an array, are skipped rather than being errors, so the result may be
+// empty. Matches are in document order. If an object has duplicate keys, a
+// key matches the first of them, as in Key. Returns ErrQuery if the
+// expression is malformed.
+func (v *Value) Query(expr string) ([]*Value, error) {
+	steps, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	matches := []*Value{v}
+	for _, step := range steps {
+		var next []*Value
+		for _, m := range matches {
+			next = step.match(m, next)
+		}
+		matches = next
+	}
+	return matches, nil
+}
+
+// Adds the children of a value that the step matches to matches.
+func (s queryStep) match(v *Value, matches []*Value) []*Value {
+	switch {
+	case s.wildcard:
+		matches = append(matches, v.arrayValue...)
+		for _, p := range v.objectValue {
+			matches = append(matches, p.val)
+		}
+	case s.isIndex:
+		if v.jsonType == Array && s.index < len(v.arrayValue) {
+			matches = append(matches, v.arrayValue[s.index])
+		}
+	case v.jsonType == Object:
+		if val, ok := v.lookup(s.key); ok {
+			matches = append(matches, val)
+		}
+	}
+	return matches
+}
+
+// Splits a query expression into its steps.
+func parseQuery(expr string) ([]queryStep, error) {
+	var steps []queryStep
+	for i := 0; i < len(expr); {
+		switch {
+		case expr[i] == '[':
+			step, n, err := parseBracket(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			i += n
+		case i == 0 && expr[i] != '.' && expr[i] != ']', i > 0 && expr[i] == '.':
+			if i > 0 {
+				i++
+			}
+			end := i + strings.IndexAny(expr[i:], ".[]")
+			if end < i {
+				end = len(expr)
+			}
+			if end == i {
+				return nil, fmt.Errorf("%w: expected a key at %d in %q", ErrQuery, i, expr)
+			}
+			if name := expr[i:end]; name == "*" {
+				steps = append(steps, queryStep{wildcard: true})
+			} else {
+				steps = append(steps, queryStep{key: name})
+			}
+			i = end
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d in %q", ErrQuery, expr[i], i, expr)
+		}
+	}
+	return steps, nil
+}
+
+// Parses the bracketed step at the start of expr[i:], reporting how many
+// bytes it took up.
+func parseBracket(expr string, i int) (queryStep, int, error) {
+	rest := expr[i+1:]
+	if strings.HasPrefix(rest, `"`) {
+		// A quoted key, which may hold brackets and escaped quotes
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end+1 >= len(rest) || rest[end+1] != ']' {
+			return queryStep{}, 0, fmt.Errorf("%w: unterminated key at %d in %q", ErrQuery, i, expr)
+		}
+		key, err := ParseString(rest[:end+1])
+		if err != nil {
+			return queryStep{}, 0, fmt.Errorf("%w: invalid key at %d in %q", ErrQuery, i, expr)
+		}
+		return queryStep{key: key.stringValue}, end + 3, nil
+	}
+
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return queryStep{}, 0, fmt.Errorf("%w: unterminated index at %d in %q", ErrQuery, i, expr)
+	}
+	if token := rest[:end]; token == "*" {
+		return queryStep{wildcard: true}, end + 2, nil
+	} else if idx, err := parseIndex(token); err == nil && token != "-" {
+		// An index too large for an int can't match anything anyway
+		return queryStep{index: idx, isIndex: true}, end + 2, nil
+	}
+	return queryStep{}, 0, fmt.Errorf("%w: invalid index %q at %d in %q", ErrQuery, rest[:end], i, expr)
+}
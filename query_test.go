@@ -0,0 +1,82 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	val := MustParse(`{
+		"members": [{"name": "a", "age": 1}, {"name": "b"}, {"age": 3}],
+		"a": {"b": {"c": true}},
+		"items": [10, 20],
+		"odd.key": {"[x]": "y", "q\"": "z"},
+		"dup": 1, "dup": 2
+	}`)
+	for _, test := range []struct {
+		expr     string
+		expected string
+	}{
+		{``, val.String()},
+		{`members[*].name`, `["a", "b"]`},
+		{`members[*].age`, `[1, 3]`},
+		{`members[1]`, `[{"name": "b"}]`},
+		{`a.b.c`, `[true]`},
+		{`a["b"].c`, `[true]`},
+		{`["a"]`, `[{"b": {"c": true}}]`},
+		{`items[0]`, `[10]`},
+		{`items[2]`, `[]`},
+		{`items[99999999999999999999]`, `[]`},
+		{`items.0`, `[]`},
+		{`items[*]`, `[10, 20]`},
+		{`a.*`, `[{"c": true}]`},
+		{`a.*.*`, `[true]`},
+		{`*`, `[[{"name": "a", "age": 1}, {"name": "b"}, {"age": 3}], {"b": {"c": true}}, [10, 20], {"[x]": "y", "q\"": "z"}, 1, 2]`},
+		{`["odd.key"]["[x]"]`, `["y"]`},
+		{`["odd.key"]["q\""]`, `["z"]`},
+		{`dup`, `[1]`},
+		{`missing.a[0]`, `[]`},
+		{`a.b.c.d`, `[]`},
+		{`a[0]`, `[]`},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			matches, err := val.Query(test.expr)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if test.expr == "" {
+				if len(matches) != 1 || matches[0] != val {
+					t.Errorf("expected %v got %v", val, matches)
+				}
+				return
+			}
+			if actual := NewArray(matches...); !equals(MustParse(test.expected), actual) {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+
+	for _, expr := range []string{
+		`.a`,
+		`a.`,
+		`a..b`,
+		`a]`,
+		`a[`,
+		`a[0`,
+		`a[-1]`,
+		`a[-]`,
+		`a[01]`,
+		`a[x]`,
+		`a[]`,
+		`a["b]`,
+		`a["b"`,
+		`a["\x"]`,
+		`a[0]b`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := val.Query(expr); !errors.Is(err, ErrQuery) {
+				t.Errorf("expected %v got %v", ErrQuery, err)
+			}
+		})
+	}
+}
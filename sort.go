@@ -0,0 +1,91 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Sorts the elements of an array in place, ordered by less, which reports
+// whether a should come before b. The sort is stable, so elements that are
+// neither less than nor greater than each other keep their original order.
+// Returns ErrType if the value is not an array, nil otherwise.
+func (v *Value) Sort(less func(a, b *Value) bool) error {
+	if v.jsonType != Array {
+		return v.typeError(Array)
+	}
+	sort.SliceStable(v.arrayValue, func(i, j int) bool {
+		return less(v.arrayValue[i], v.arrayValue[j])
+	})
+	return nil
+}
+
+// Sorts an array of strings in place in increasing byte order. Returns
+// ErrType if the value is not an array or any element is not a string, in
+// which case the array is left unchanged.
+func (v *Value) SortStrings() error {
+	if err := v.checkElements(String); err != nil {
+		return err
+	}
+	return v.Sort(func(a, b *Value) bool {
+		return a.stringValue < b.stringValue
+	})
+}
+
+// Sorts an array of numbers in place in increasing order. Integers and
+// Numbers may be mixed and are compared exactly by their numeric value,
+// and NaN sorts before every other number. Returns ErrType if the value is
+// not an array or any element is not a number, in which case the array is
+// left unchanged.
+func (v *Value) SortNumbers() error {
+	if err := v.checkElements(Integer, Number); err != nil {
+		return err
+	}
+	return v.Sort(func(a, b *Value) bool {
+		return compareNumbers(a, b) < 0
+	})
+}
+
+// Checks that a value is an array whose elements all have one of the
+// given types.
+func (v *Value) checkElements(types ...Type) error {
+	if v.jsonType != Array {
+		return v.typeError(Array)
+	}
+	for i, elem := range v.arrayValue {
+		ok := false
+		for _, t := range types {
+			ok = ok || elem.jsonType == t
+		}
+		if !ok {
+			return fmt.Errorf("%w at index %d", elem.typeError(types...), i)
+		}
+	}
+	return nil
+}
+
+// Compares two Integers or Numbers by their numeric value, returning -1, 0
+// or 1. NaN is less than every other number and equal to itself.
+func compareNumbers(a, b *Value) int {
+	aNaN := a.jsonType == Number && math.IsNaN(a.numberValue)
+	bNaN := b.jsonType == Number && math.IsNaN(b.numberValue)
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	}
+	return numberFloat(a).Cmp(numberFloat(b))
+}
+
+// Converts an Integer or a Number that isn't NaN to a big.Float, which holds
+// any int64, big.Int or float64 exactly.
+func numberFloat(v *Value) *big.Float {
+	if v.jsonType == Number {
+		return big.NewFloat(v.numberValue)
+	}
+	return new(big.Float).SetInt(v.bigInt())
+}
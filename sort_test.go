@@ -0,0 +1,73 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	val := MustParse(`[{"n": 3, "i": 0}, {"n": 1, "i": 1}, {"n": 3, "i": 2}, {"n": 2, "i": 3}]`)
+	err := val.Sort(func(a, b *Value) bool {
+		return a.Key("n").IntOr(0) < b.Key("n").IntOr(0)
+	})
+	if err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	expected := MustParse(`[{"n": 1, "i": 1}, {"n": 2, "i": 3}, {"n": 3, "i": 0}, {"n": 3, "i": 2}]`)
+	if !Equal(expected, val) {
+		t.Errorf("expected %v got %v", expected, val)
+	}
+
+	if err := NewObject().Sort(func(a, b *Value) bool { return false }); !errors.Is(err, ErrType) {
+		t.Errorf("expected %v got %v", ErrType, err)
+	}
+}
+
+func TestSortStrings(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{`[]`, `[]`, nil},
+		{`["b", "a", "c", "B", "a"]`, `["B", "a", "a", "b", "c"]`, nil},
+		{`["b", 1, "a"]`, `["b", 1, "a"]`, ErrType},
+		{`{}`, `{}`, ErrType},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			if err := val.SortStrings(); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if expected := MustParse(test.expected); !Equal(expected, val) {
+				t.Errorf("expected %v got %v", expected, val)
+			}
+		})
+	}
+}
+
+func TestSortNumbers(t *testing.T) {
+	for _, test := range []struct {
+		input    *Value
+		expected *Value
+		err      error
+	}{
+		{MustParse(`[]`), MustParse(`[]`), nil},
+		{MustParse(`[3, -1.5, 2, 0, 2.5]`), MustParse(`[-1.5, 0, 2, 2.5, 3]`), nil},
+		{MustParse(`[9007199254740993, 9007199254740992.0, 100000000000000000000, -1e30]`), MustParse(`[-1e30, 9007199254740992.0, 9007199254740993, 100000000000000000000]`), nil},
+		{MustParse(`[2, 2.0, 1]`), MustParse(`[1, 2, 2.0]`), nil},
+		{NewArray(NewInt(1), NewNumber(math.Inf(-1)), NewNumber(math.NaN())), NewArray(NewNumber(math.NaN()), NewNumber(math.Inf(-1)), NewInt(1)), nil},
+		{MustParse(`[2, "1"]`), MustParse(`[2, "1"]`), ErrType},
+		{MustParse(`null`), MustParse(`null`), ErrType},
+	} {
+		t.Run(test.input.String(), func(t *testing.T) {
+			if err := test.input.SortNumbers(); !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if test.input.String() != test.expected.String() {
+				t.Errorf("expected %v got %v", test.expected, test.input)
+			}
+		})
+	}
+}
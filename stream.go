@@ -0,0 +1,184 @@
+package json
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reads a sequence of top-level JSON values from a stream, such as
+// newline-delimited JSON. Values may be separated by any whitespace,
+// and the stream is read incrementally rather than all at once.
+type StreamDecoder struct {
+	r      *bufio.Reader
+	err    error
+	pos    int
+	line   int
+	column int
+}
+
+// Creates a new decoder that reads values from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: bufio.NewReader(r), line: 1, column: 1}
+}
+
+// Reads the next value from the stream. Returns io.EOF when there are no
+// more values. If a value cannot be read, it returns a null value and a
+// non-nil error, and every later call will return the same error.
+func (d *StreamDecoder) Next() (*Value, error) {
+	if d.err != nil {
+		return &Value{}, d.err
+	}
+
+	pda := newParser(DefaultParseOptions())
+	pda.pos, pda.line, pda.column = d.pos, d.line, d.column
+	for pda.isRunning {
+		if pda.isBlank() {
+			if _, _, err := d.r.ReadRune(); errors.Is(err, io.EOF) {
+				d.err = io.EOF
+				return &Value{}, d.err
+			}
+			d.r.UnreadRune()
+		}
+		if err := pda.next(d.r); err != nil {
+			d.err = err
+			return &Value{}, d.err
+		}
+		if pda.isComplete() {
+			break
+		}
+	}
+
+	d.pos, d.line, d.column = pda.pos, pda.line, pda.column
+	return pda.valueStack[0], nil
+}
+
+// Reads the elements of a top-level JSON array one at a time, so that a
+// huge array of records can be processed without holding all of it in
+// memory. Only the element being decoded is built as a Value.
+type ArrayDecoder struct {
+	t      *Tokenizer
+	token  Token
+	peeked bool
+	begun  bool
+	err    error
+}
+
+// Creates a new decoder that reads the elements of the array in r.
+func NewArrayDecoder(r io.Reader) *ArrayDecoder {
+	return &ArrayDecoder{t: NewTokenizer(r)}
+}
+
+// Reports whether there is another element in the array. Returns false at
+// the end of the array, or if the input is not a valid array, in which case
+// Decode returns the error.
+func (d *ArrayDecoder) More() bool {
+	token, err := d.peek()
+	return err == nil && token.Kind != TokenArrayEnd
+}
+
+// Reads the next element of the array. Returns io.EOF after the last
+// element. Returns ErrType if the input doesn't start with an array, and
+// an error if the input is not valid JSON. After an error, every later
+// call will return the same error.
+func (d *ArrayDecoder) Decode() (*Value, error) {
+	token, err := d.peek()
+	if err != nil {
+		return &Value{}, err
+	}
+	if token.Kind == TokenArrayEnd {
+		return &Value{}, io.EOF
+	}
+	d.peeked = false
+	v, err := d.value(token)
+	if err != nil {
+		d.err = err
+		return &Value{}, err
+	}
+	return v, nil
+}
+
+// Gets the token that starts the next element without consuming it,
+// checking first that the input starts with an array.
+func (d *ArrayDecoder) peek() (Token, error) {
+	if d.err != nil {
+		return Token{}, d.err
+	}
+	if !d.begun {
+		d.begun = true
+		token, err := d.t.Token()
+		if err == io.EOF {
+			err = fmt.Errorf("%w: expected an array", ErrUnexpectedEOF)
+		} else if err == nil && token.Kind != TokenArrayStart {
+			err = fmt.Errorf("%w: expected %v, got %v", ErrType, TokenArrayStart, token.Kind)
+		}
+		if err != nil {
+			d.err = err
+			return Token{}, err
+		}
+	}
+	if !d.peeked {
+		token, err := d.t.Token()
+		if err != nil {
+			d.err = err
+			return Token{}, err
+		}
+		d.token, d.peeked = token, true
+	}
+	return d.token, nil
+}
+
+// Builds the value that starts with the given token from the tokens after it.
+func (d *ArrayDecoder) value(token Token) (*Value, error) {
+	switch token.Kind {
+	case TokenArrayStart:
+		arr := NewArray()
+		for {
+			token, err := d.t.Token()
+			if err != nil {
+				return nil, err
+			}
+			if token.Kind == TokenArrayEnd {
+				return arr, nil
+			}
+			val, err := d.value(token)
+			if err != nil {
+				return nil, err
+			}
+			arr.arrayValue = append(arr.arrayValue, val)
+		}
+	case TokenObjectStart:
+		obj := NewObject()
+		for {
+			key, err := d.t.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key.Kind == TokenObjectEnd {
+				return obj, nil
+			}
+			token, err := d.t.Token()
+			if err != nil {
+				return nil, err
+			}
+			val, err := d.value(token)
+			if err != nil {
+				return nil, err
+			}
+			obj.objectValue = append(obj.objectValue, pair{key: key.Value.stringValue, val: val})
+		}
+	default:
+		return token.Value, nil
+	}
+}
+
+// Whether nothing but whitespace and comments has been read so far.
+func (p *parser) isBlank() bool {
+	return p.valueTop == -1 && len(p.buffer) == 0 && (p.state == sr || p.state == c2)
+}
+
+// Whether a whole top-level value has been read.
+func (p *parser) isComplete() bool {
+	return p.state == ok && p.modeTop == 0
+}
@@ -0,0 +1,182 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	input := `{"a": 1}
+	[1, 2]
+	// comment
+	"str" 5 -5.5e1
+	true false null{}[]
+	/* comment */ 7`
+	expected := []*Value{
+		{jsonType: Object, objectValue: []pair{{"a", &Value{jsonType: Integer, integerValue: 1}}}},
+		{jsonType: Array, arrayValue: []*Value{
+			{jsonType: Integer, integerValue: 1},
+			{jsonType: Integer, integerValue: 2},
+		}},
+		{jsonType: String, stringValue: "str"},
+		{jsonType: Integer, integerValue: 5},
+		{jsonType: Number, numberValue: -5.5e1},
+		{jsonType: Boolean, booleanValue: true},
+		{jsonType: Boolean, booleanValue: false},
+		{jsonType: Null},
+		{jsonType: Object, objectValue: []pair{}},
+		{jsonType: Array, arrayValue: []*Value{}},
+		{jsonType: Integer, integerValue: 7},
+	}
+
+	d := NewStreamDecoder(strings.NewReader(input))
+	for _, e := range expected {
+		actual, err := d.Next()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if !equals(e, actual) {
+			t.Errorf("expected %v got %v", e, actual)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := d.Next(); err != io.EOF {
+			t.Errorf("expected %v got %v", io.EOF, err)
+		}
+	}
+}
+
+func TestStreamDecoderEmpty(t *testing.T) {
+	for _, input := range []string{"", " \n\t", "// comment", "/* comment */\n"} {
+		t.Run(input, func(t *testing.T) {
+			d := NewStreamDecoder(strings.NewReader(input))
+			if _, err := d.Next(); err != io.EOF {
+				t.Errorf("expected %v got %v", io.EOF, err)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderInvalid(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		valid  int
+		line   int
+		column int
+	}{
+		{"1\n2\n[3,", 2, 3, 4},
+		{"{}\n  tru", 1, 2, 6},
+		{"1\n/* comment", 1, 2, 11},
+		{"{}\n  ]", 1, 2, 3},
+		{"1[]", 0, 1, 2},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			d := NewStreamDecoder(strings.NewReader(test.input))
+			for i := 0; i < test.valid; i++ {
+				if _, err := d.Next(); err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+			}
+			_, err := d.Next()
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError got %v", err)
+			}
+			if parseErr.Line != test.line || parseErr.Column != test.column {
+				t.Errorf("expected %v:%v got %v:%v", test.line, test.column, parseErr.Line, parseErr.Column)
+			}
+			if _, again := d.Next(); again != err {
+				t.Errorf("expected %v got %v", err, again)
+			}
+		})
+	}
+
+	d := NewStreamDecoder(mockFileErrorOnRead{})
+	if _, err := d.Next(); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected %v got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestArrayDecoder(t *testing.T) {
+	input := `// records
+	[
+		{"id": 1, "tags": ["a", "b"], "meta": {"x": null}},
+		2.5, "three", [], {}, true,
+	]`
+	expected := []*Value{
+		MustParse(`{"id": 1, "tags": ["a", "b"], "meta": {"x": null}}`),
+		NewNumber(2.5),
+		NewString("three"),
+		NewArray(),
+		NewObject(),
+		NewBool(true),
+	}
+
+	d := NewArrayDecoder(strings.NewReader(input))
+	for _, e := range expected {
+		if !d.More() {
+			t.Fatalf("expected %v got %v", true, false)
+		}
+		actual, err := d.Decode()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if !equals(e, actual) {
+			t.Errorf("expected %v got %v", e, actual)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if d.More() {
+			t.Errorf("expected %v got %v", false, true)
+		}
+		if _, err := d.Decode(); err != io.EOF {
+			t.Errorf("expected %v got %v", io.EOF, err)
+		}
+	}
+}
+
+func TestArrayDecoderEmpty(t *testing.T) {
+	d := NewArrayDecoder(strings.NewReader(" [ ] "))
+	if d.More() {
+		t.Errorf("expected %v got %v", false, true)
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
+
+func TestArrayDecoderInvalid(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		elements int
+		err      error
+	}{
+		{``, 0, ErrUnexpectedEOF},
+		{`{"a": 1}`, 0, ErrType},
+		{`5`, 0, ErrType},
+		{`[1, 2`, 2, ErrUnexpectedEOF},
+		{`[1, {"a": [}]`, 1, ErrParse},
+		{`[1 2]`, 1, ErrParse},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			d := NewArrayDecoder(strings.NewReader(test.input))
+			for i := 0; i < test.elements; i++ {
+				if _, err := d.Decode(); err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+			}
+			if d.More() {
+				if _, err := d.Decode(); !errors.Is(err, test.err) {
+					t.Errorf("expected %v got %v", test.err, err)
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if _, err := d.Decode(); !errors.Is(err, test.err) {
+					t.Errorf("expected %v got %v", test.err, err)
+				}
+			}
+		})
+	}
+}
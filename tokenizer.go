@@ -0,0 +1,136 @@
+package json
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// The kind of a token read by a Tokenizer.
+type TokenKind int
+
+// Possible token kinds
+const (
+	TokenObjectStart TokenKind = iota
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenKey
+	TokenString
+	TokenNumber
+	TokenInteger
+	TokenBoolean
+	TokenNull
+	numTokenKinds
+)
+
+var tokenKindStrings = [numTokenKinds]string{
+	"<object start>",
+	"<object end>",
+	"<array start>",
+	"<array end>",
+	"<key>",
+	"<string>",
+	"<number>",
+	"<integer>",
+	"<boolean>",
+	"<null>",
+}
+
+// Maps the type of a literal value to the kind of token it produces.
+var tokenKinds = [numTypes]TokenKind{
+	Null:    TokenNull,
+	Number:  TokenNumber,
+	Integer: TokenInteger,
+	String:  TokenString,
+	Boolean: TokenBoolean,
+}
+
+// Returns a string representation of a token kind.
+func (k TokenKind) String() string {
+	if k < 0 || k >= numTokenKinds {
+		return "<unknown>"
+	}
+	return tokenKindStrings[k]
+}
+
+// A single piece of a JSON document.
+type Token struct {
+	Kind TokenKind
+	// The key, string, number, integer, boolean or null that was read.
+	// Nil for the start and end of arrays and objects.
+	Value *Value
+	// The byte offset in the input where the token starts.
+	Offset int
+}
+
+// Adds a token to the queue if the parser is tokenizing.
+func (p *parser) emit(kind TokenKind, v *Value, offset int) {
+	if p.tokenize {
+		p.tokens = append(p.tokens, Token{Kind: kind, Value: v, Offset: offset})
+	}
+}
+
+// Reads a stream of JSON values one token at a time without building
+// the whole value in memory. Like StreamDecoder, the stream may contain
+// any number of top-level values separated by whitespace.
+type Tokenizer struct {
+	r      *bufio.Reader
+	pda    *parser
+	err    error
+	tokens []Token
+}
+
+// Creates a new tokenizer that reads from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	t := &Tokenizer{r: bufio.NewReader(r)}
+	t.pda = newParser(DefaultParseOptions())
+	t.pda.tokenize = true
+	return t
+}
+
+// Reads the next token from the stream. Returns io.EOF when there are
+// no more tokens. If the input is not valid JSON, it returns a non-nil
+// error, and every later call will return the same error.
+func (t *Tokenizer) Token() (Token, error) {
+	for len(t.tokens) == 0 {
+		if t.err != nil {
+			return Token{}, t.err
+		}
+		t.err = t.read()
+	}
+	token := t.tokens[0]
+	t.tokens = t.tokens[1:]
+	return token, nil
+}
+
+// Runs the parser until it produces at least one token or fails.
+func (t *Tokenizer) read() error {
+	pda := t.pda
+	for len(pda.tokens) == 0 {
+		if !pda.isRunning && !pda.isComplete() {
+			return pda.errorf(ErrUnexpectedEOF, "unexpected end of input")
+		}
+		if pda.isComplete() {
+			next := newParser(pda.options)
+			next.tokenize = true
+			next.pos, next.line, next.column = pda.pos, pda.line, pda.column
+			pda, t.pda = next, next
+		}
+		if pda.isBlank() {
+			if _, _, err := t.r.ReadRune(); errors.Is(err, io.EOF) {
+				return io.EOF
+			}
+			t.r.UnreadRune()
+		}
+		if err := pda.next(t.r); err != nil {
+			// Tokens read before the error still come out first
+			t.tokens = append(t.tokens, pda.tokens...)
+			pda.tokens = pda.tokens[:0]
+			return err
+		}
+	}
+	t.tokens = append(t.tokens, pda.tokens...)
+	pda.tokens = pda.tokens[:0]
+	return nil
+}
@@ -0,0 +1,115 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	input := `{"a": [1, -2.5, "x"], "b": {}, "c": [], "d": {"e": null}} true false 7`
+	expected := []Token{
+		{TokenObjectStart, nil, 0},
+		{TokenKey, &Value{jsonType: String, stringValue: "a"}, 1},
+		{TokenArrayStart, nil, 6},
+		{TokenInteger, &Value{jsonType: Integer, integerValue: 1}, 7},
+		{TokenNumber, &Value{jsonType: Number, numberValue: -2.5}, 10},
+		{TokenString, &Value{jsonType: String, stringValue: "x"}, 16},
+		{TokenArrayEnd, nil, 19},
+		{TokenKey, &Value{jsonType: String, stringValue: "b"}, 22},
+		{TokenObjectStart, nil, 27},
+		{TokenObjectEnd, nil, 28},
+		{TokenKey, &Value{jsonType: String, stringValue: "c"}, 31},
+		{TokenArrayStart, nil, 36},
+		{TokenArrayEnd, nil, 37},
+		{TokenKey, &Value{jsonType: String, stringValue: "d"}, 40},
+		{TokenObjectStart, nil, 45},
+		{TokenKey, &Value{jsonType: String, stringValue: "e"}, 46},
+		{TokenNull, &Value{jsonType: Null}, 51},
+		{TokenObjectEnd, nil, 55},
+		{TokenObjectEnd, nil, 56},
+		{TokenBoolean, &Value{jsonType: Boolean, booleanValue: true}, 58},
+		{TokenBoolean, &Value{jsonType: Boolean, booleanValue: false}, 63},
+		{TokenInteger, &Value{jsonType: Integer, integerValue: 7}, 69},
+	}
+
+	tokenizer := NewTokenizer(strings.NewReader(input))
+	for _, e := range expected {
+		actual, err := tokenizer.Token()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if actual.Kind != e.Kind || actual.Offset != e.Offset {
+			t.Errorf("expected %v at %v got %v at %v", e.Kind, e.Offset, actual.Kind, actual.Offset)
+		}
+		if (e.Value == nil) != (actual.Value == nil) || (e.Value != nil && !equals(e.Value, actual.Value)) {
+			t.Errorf("expected %v got %v", e.Value, actual.Value)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := tokenizer.Token(); err != io.EOF {
+			t.Errorf("expected %v got %v", io.EOF, err)
+		}
+	}
+}
+
+func TestTokenizerDoesNotBuildTree(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader(`[[1, 2], {"a": 3}]`))
+	token, _ := tokenizer.Token()
+	for {
+		if _, err := tokenizer.Token(); err != nil {
+			break
+		}
+	}
+	if v := tokenizer.pda.valueStack[0]; token.Kind != TokenArrayStart || (v != nil && len(v.arrayValue) != 0) {
+		t.Errorf("expected empty array got %v", tokenizer.pda.valueStack[0])
+	}
+}
+
+func TestTokenizerInvalid(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		valid int
+	}{
+		{`[1, }`, 2},
+		{`{"a" 1}`, 2},
+		{`[1`, 2},
+		{`{"a": 1`, 3},
+		{`1 ]`, 1},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			tokenizer := NewTokenizer(strings.NewReader(test.input))
+			for i := 0; i < test.valid; i++ {
+				if _, err := tokenizer.Token(); err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+			}
+			_, err := tokenizer.Token()
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if _, again := tokenizer.Token(); again != err {
+				t.Errorf("expected %v got %v", err, again)
+			}
+		})
+	}
+}
+
+func TestTokenKindStrings(t *testing.T) {
+	for _, test := range []struct {
+		input    TokenKind
+		expected string
+	}{
+		{TokenObjectStart, "<object start>"},
+		{TokenNull, "<null>"},
+		{numTokenKinds, "<unknown>"},
+		{-1, "<unknown>"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			if actual := test.input.String(); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
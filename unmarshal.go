@@ -0,0 +1,353 @@
+package json
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	valueType           = reflect.TypeOf(Value{})
+	bigIntType          = reflect.TypeOf(big.Int{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// A struct field that maps to an object member.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// Cache of the fields of each struct type, since finding them is expensive.
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// Gets the fields of a struct type that map to object members, honoring
+// `json:"name,omitempty"` tags and promoting fields of embedded structs.
+// Fields tagged `json:"-"` and unexported fields are skipped.
+func typeFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+
+	all := []field{}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(append([]int{}, index...), i)
+
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				// Promote the fields of embedded structs
+				walk(ft, idx)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			all = append(all, field{
+				name:      name,
+				index:     idx,
+				omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			})
+		}
+	}
+	walk(t, nil)
+
+	// When names collide, the least deeply embedded field wins.
+	shallowest := map[string]int{}
+	for _, f := range all {
+		if d, ok := shallowest[f.name]; !ok || len(f.index) < d {
+			shallowest[f.name] = len(f.index)
+		}
+	}
+	fields := []field{}
+	for _, f := range all {
+		if len(f.index) == shallowest[f.name] {
+			fields = append(fields, f)
+			shallowest[f.name] = -1
+		}
+	}
+
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.([]field)
+}
+
+// Parses JSON from a byte slice and stores the result in the value pointed
+// to by v, using reflection. Object members are matched to struct fields by
+// their `json:"name"` tags or field names, case-insensitively if there is no
+// exact match. Slices, arrays, maps with string or integer keys, pointers,
+// big.Int, *Value fields and types implementing encoding.TextUnmarshaler are
+// supported.
+// A []byte is decoded from a base64 string.
+//
+// Returns ErrParse if the JSON is invalid or a []byte isn't valid base64,
+// the same as AsBytes, ErrType if a value doesn't fit in the corresponding
+// Go type, and ErrRange if a number overflows it.
+func Unmarshal(data []byte, v any) error {
+	val, err := ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return UnmarshalValue(val, v)
+}
+
+// Stores an already parsed value in the value pointed to by v.
+// See Unmarshal for how values are converted.
+func UnmarshalValue(val *Value, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: cannot unmarshal into non-pointer %T", ErrType, v)
+	}
+	return decode(val, rv.Elem(), "")
+}
+
+// Stores a value into a settable reflect.Value. The path is a JSON Pointer
+// to the value being decoded, for use in error messages.
+func decode(val *Value, rv reflect.Value, path string) error {
+	// Values can be stored as they are.
+	switch rv.Type() {
+	case valueType:
+		rv.Set(reflect.ValueOf(*val))
+		return nil
+	case reflect.PtrTo(valueType):
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		if val.jsonType == Null {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(val, rv.Elem(), path)
+	}
+
+	if rv.Type() == bigIntType && val.jsonType == Integer {
+		rv.Set(reflect.ValueOf(val.bigInt()).Elem())
+		return nil
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) && val.jsonType == String {
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val.stringValue)); err != nil {
+			return fmt.Errorf("%w: cannot unmarshal %q into %v at %q: %v", ErrType, val.stringValue, rv.Type(), path, err)
+		}
+		return nil
+	}
+
+	if val.jsonType == Null {
+		// Like encoding/json, null leaves non-nullable values alone.
+		if rv.Kind() == reflect.Interface || rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice {
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("%w: cannot unmarshal %v into %v at %q", ErrType, val.jsonType, rv.Type(), path)
+	}
+	overflow := func() error {
+		return fmt.Errorf("%w: %v overflows %v at %q", ErrRange, val, rv.Type(), path)
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return mismatch()
+		}
+		if i := val.ToGo(); i != nil {
+			rv.Set(reflect.ValueOf(i))
+		}
+	case reflect.Bool:
+		if val.jsonType != Boolean {
+			return mismatch()
+		}
+		rv.SetBool(val.booleanValue)
+	case reflect.String:
+		if val.jsonType != String {
+			return mismatch()
+		}
+		rv.SetString(val.stringValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val.jsonType != Integer {
+			return mismatch()
+		}
+		if val.bigValue != nil || rv.OverflowInt(val.integerValue) {
+			return overflow()
+		}
+		rv.SetInt(val.integerValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if val.jsonType != Integer {
+			return mismatch()
+		}
+		b := val.bigInt()
+		if b.Sign() < 0 || !b.IsUint64() || rv.OverflowUint(b.Uint64()) {
+			return overflow()
+		}
+		rv.SetUint(b.Uint64())
+	case reflect.Float32, reflect.Float64:
+		f, err := val.AsNumber()
+		if err != nil {
+			return mismatch()
+		}
+		if rv.OverflowFloat(f) {
+			return overflow()
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 && val.jsonType == String {
+			b, err := val.AsBytes()
+			if err != nil {
+				return fmt.Errorf("%w at %q", err, path)
+			}
+			rv.SetBytes(b)
+			return nil
+		}
+		if val.jsonType != Array {
+			return mismatch()
+		}
+		s := reflect.MakeSlice(rv.Type(), len(val.arrayValue), len(val.arrayValue))
+		for i, elem := range val.arrayValue {
+			if err := decode(elem, s.Index(i), path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		if val.jsonType != Array {
+			return mismatch()
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(val.arrayValue) {
+				rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+				continue
+			}
+			if err := decode(val.arrayValue[i], rv.Index(i), path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if val.jsonType != Object {
+			return mismatch()
+		}
+		t := rv.Type()
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return fmt.Errorf("%w: unsupported map key type %v at %q", ErrType, t.Key(), path)
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(t))
+		}
+		for _, p := range val.objectValue {
+			elemPath := path + formatPointer([]string{p.key})
+			key, err := decodeMapKey(p.key, t.Key())
+			if err != nil {
+				return fmt.Errorf("%w: cannot use %q as %v at %q", ErrType, p.key, t.Key(), elemPath)
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := decode(p.val, elem, elemPath); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		if val.jsonType != Object {
+			return mismatch()
+		}
+		fields := typeFields(rv.Type())
+		for _, p := range val.objectValue {
+			f, ok := findField(fields, p.key)
+			if !ok {
+				continue
+			}
+			fv, err := fieldByIndex(rv, f.index)
+			if err != nil {
+				return fmt.Errorf("%w at %q", err, path)
+			}
+			if err := decode(p.val, fv, path+formatPointer([]string{p.key})); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unsupported type %v at %q", ErrType, rv.Type(), path)
+	}
+	return nil
+}
+
+// Finds the struct field for an object key, preferring an exact match
+// over a case-insensitive one.
+func findField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// Gets a nested struct field, allocating any nil embedded struct pointers
+// along the way.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("%w: cannot set embedded pointer to unexported struct %v", ErrType, rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+// Converts an object key to a map key of the given type.
+func decodeMapKey(key string, t reflect.Type) (reflect.Value, error) {
+	kv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, t.Bits())
+		if err != nil {
+			return kv, err
+		}
+		kv.SetInt(i)
+	default:
+		u, err := strconv.ParseUint(key, 10, t.Bits())
+		if err != nil {
+			return kv, err
+		}
+		kv.SetUint(u)
+	}
+	return kv, nil
+}
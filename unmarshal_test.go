@@ -0,0 +1,188 @@
+package json
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEmbedded struct {
+	Embedded string
+	Shadowed string
+}
+
+type testStruct struct {
+	testEmbedded
+	Name     string `json:"name"`
+	Age      uint8  `json:"age,omitempty"`
+	Score    float64
+	Tags     []string
+	Counts   map[string]int
+	IDs      map[int]bool
+	Nested   *testStruct
+	Ratio    [2]float32
+	Any      any
+	Raw      *Value
+	Time     time.Time
+	Bytes    []byte
+	Shadowed int
+	Skipped  string `json:"-"`
+	private  string
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := `{
+		"name": "John",
+		"age": 30,
+		"score": 1.5,
+		"Tags": ["a", "b"],
+		"Counts": {"x": 1, "y": 2},
+		"IDs": {"1": true, "-2": false},
+		"Nested": {"name": "Paul", "Nested": null},
+		"Ratio": [0.5],
+		"Any": [1, 2.5, "s", null, {"k": true}],
+		"Raw": {"a": [1]},
+		"Time": "2006-01-02T15:04:05Z",
+		"Bytes": "aGVsbG8=",
+		"Embedded": "e",
+		"Shadowed": 7,
+		"Skipped": "no",
+		"private": "no",
+		"unknown": "ignored"
+	}`
+	actual := testStruct{}
+	if err := Unmarshal([]byte(input), &actual); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	raw, _ := ParseString(`{"a": [1]}`)
+	expected := testStruct{
+		testEmbedded: testEmbedded{Embedded: "e"},
+		Name:         "John",
+		Age:          30,
+		Score:        1.5,
+		Tags:         []string{"a", "b"},
+		Counts:       map[string]int{"x": 1, "y": 2},
+		IDs:          map[int]bool{1: true, -2: false},
+		Nested:       &testStruct{Name: "Paul"},
+		Ratio:        [2]float32{0.5, 0},
+		Any:          []any{int64(1), 2.5, "s", nil, map[string]any{"k": true}},
+		Time:         time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		Bytes:        []byte("hello"),
+		Shadowed:     7,
+	}
+	if !Equal(actual.Raw, raw) {
+		t.Errorf("expected %v got %v", raw, actual.Raw)
+	}
+	actual.Raw = nil
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v\ngot %+v", expected, actual)
+	}
+}
+
+func TestUnmarshalScalars(t *testing.T) {
+	var i int
+	if err := Unmarshal([]byte(`-5`), &i); err != nil || i != -5 {
+		t.Errorf("expected %v got %v (%v)", -5, i, err)
+	}
+	var f float32
+	if err := Unmarshal([]byte(`5`), &f); err != nil || f != 5 {
+		t.Errorf("expected %v got %v (%v)", 5, f, err)
+	}
+	var b *big.Int
+	if err := Unmarshal([]byte(`123456789012345678901234567890`), &b); err != nil || b.String() != "123456789012345678901234567890" {
+		t.Errorf("expected %v got %v (%v)", "123456789012345678901234567890", b, err)
+	}
+	var p *string
+	if err := Unmarshal([]byte(`"s"`), &p); err != nil || *p != "s" {
+		t.Errorf("expected %v got %v (%v)", "s", p, err)
+	}
+	if err := Unmarshal([]byte(`null`), &p); err != nil || p != nil {
+		t.Errorf("expected %v got %v (%v)", nil, p, err)
+	}
+	s := "unchanged"
+	if err := Unmarshal([]byte(`null`), &s); err != nil || s != "unchanged" {
+		t.Errorf("expected %v got %v (%v)", "unchanged", s, err)
+	}
+	var a any
+	if err := Unmarshal([]byte(`123456789012345678901234567890`), &a); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if n, ok := a.(*big.Int); !ok || n.String() != "123456789012345678901234567890" {
+		t.Errorf("expected %v got %v", "123456789012345678901234567890", a)
+	}
+	var v Value
+	if err := Unmarshal([]byte(`[true]`), &v); err != nil || !Equal(&v, NewArray(NewBool(true))) {
+		t.Errorf("expected %v got %v (%v)", "[true]", &v, err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		target   any
+		expected error
+	}{
+		{`[`, &[]int{}, ErrParse},
+		{`1`, []int{}, ErrType},
+		{`1`, nil, ErrType},
+		{`1`, (*int)(nil), ErrType},
+		{`"1"`, new(int), ErrType},
+		{`1.5`, new(int), ErrType},
+		{`1`, new(string), ErrType},
+		{`1`, new(bool), ErrType},
+		{`"a"`, new(float64), ErrType},
+		{`{}`, new([]int), ErrType},
+		{`[]`, new([1]int), nil},
+		{`{}`, new([1]int), ErrType},
+		{`[]`, new(map[string]int), ErrType},
+		{`{"a": 1}`, new(map[int]int), ErrType},
+		{`{"a": 1}`, new(map[bool]int), ErrType},
+		{`[]`, new(testStruct), ErrType},
+		{`{"Tags": [1]}`, new(testStruct), ErrType},
+		{`{"Time": "yesterday"}`, new(testStruct), ErrType},
+		{`{"Bytes": "!!"}`, new(testStruct), ErrParse},
+		{`{"Bytes": 5}`, new(testStruct), ErrType},
+		{`1`, new(chan int), ErrType},
+		{`1`, new(error), ErrType},
+		{`256`, new(uint8), ErrRange},
+		{`-1`, new(uint), ErrRange},
+		{`128`, new(int8), ErrRange},
+		{`123456789012345678901234567890`, new(int64), ErrRange},
+		{`1e300`, new(float32), ErrRange},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			err := Unmarshal([]byte(test.input), test.target)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBase64MatchesAsBytes(t *testing.T) {
+	for _, input := range []string{`"!!"`, `"aGVsbG8"`, `"a-_b"`} {
+		t.Run(input, func(t *testing.T) {
+			var b []byte
+			if err := Unmarshal([]byte(input), &b); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+			if _, err := MustParse(input).AsBytes(); !errors.Is(err, ErrParse) {
+				t.Errorf("expected %v got %v", ErrParse, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrorPath(t *testing.T) {
+	err := Unmarshal([]byte(`{"Nested": {"Tags": ["a", 1]}}`), &testStruct{})
+	if err == nil || !errors.Is(err, ErrType) {
+		t.Fatalf("expected %v got %v", ErrType, err)
+	}
+	if expected := `"/Nested/Tags/1"`; !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("expected path %v got %v", expected, err)
+	}
+}
@@ -0,0 +1,89 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lists everything that made a document fail to match a template in Validate.
+type ValidationError struct {
+	// One error for each mismatch, each saying where in the document it is.
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Gets the errors for each mismatch. With Go 1.20 or later, this lets
+// errors.Is and errors.As look through to each of them.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// Checks that a document has the shape of a template, a lighter alternative
+// to a JSON Schema. Where the template has an object, the document must have
+// an object with at least the same keys, and their values must match the
+// template's values in turn, though the document may have other keys too.
+// Where the template has an array with one element, the document must have
+// an array whose elements all match that element; any other array in the
+// template only requires an array. Where the template has a scalar, the
+// document must have a scalar of the same type, except that a Number in the
+// template also allows an Integer. The template's own scalar values don't
+// matter, so {"id": 0, "tags": [""]} matches any object whose "id" is an
+// integer and whose "tags" is an array of strings.
+//
+// Returns nil if the document matches, and a *ValidationError otherwise,
+// holding an error for every mismatch: ErrType if a value has the wrong
+// type, or ErrNotFound if a key is missing, each with the JSON Pointer to
+// where it is in the document.
+func Validate(doc, template *Value) error {
+	var errs []error
+	validate(doc, template, nil, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Checks a value against a template, adding any mismatches to errs, where
+// path is the reference tokens leading to the value.
+func validate(doc, template *Value, path []string, errs *[]error) {
+	expected := []Type{template.jsonType}
+	if template.jsonType == Number {
+		expected = append(expected, Integer)
+	}
+	matches := false
+	for _, t := range expected {
+		matches = matches || doc.jsonType == t
+	}
+	if !matches {
+		*errs = append(*errs, fmt.Errorf("%w at %q", doc.typeError(expected...), formatPointer(path)))
+		return
+	}
+
+	switch template.jsonType {
+	case Object:
+		for _, p := range template.objectValue {
+			keyPath := append(path[:len(path):len(path)], p.key)
+			val, ok := doc.lookup(p.key)
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("%w: key %q at %q", ErrNotFound, p.key, formatPointer(path)))
+				continue
+			}
+			validate(val, p.val, keyPath, errs)
+		}
+	case Array:
+		if len(template.arrayValue) != 1 {
+			return
+		}
+		for i, val := range doc.arrayValue {
+			validate(val, template.arrayValue[0], append(path[:len(path):len(path)], strconv.Itoa(i)), errs)
+		}
+	}
+}
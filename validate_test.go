@@ -0,0 +1,72 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	template := MustParse(`{"id": 0, "name": "", "score": 0.0, "tags": [""], "meta": {"active": false}, "extra": [], "any": null}`)
+	for _, test := range []struct {
+		input    string
+		expected []string
+	}{
+		{`{"id": 1, "name": "a", "score": 2.5, "tags": ["x", "y"], "meta": {"active": true, "more": 1}, "extra": [1, "b"], "any": null, "other": 5}`, nil},
+		{`{"id": 1, "name": "a", "score": 2, "tags": [], "meta": {"active": true}, "extra": [], "any": null}`, nil},
+		{`{"id": 1.5, "name": "a", "score": 2, "tags": [], "meta": {"active": true}, "extra": [], "any": null}`, []string{
+			`type error: expected <integer>, got <number> at "/id"`,
+		}},
+		{`{"id": 1, "score": "high", "tags": ["x", 2, null], "meta": {}, "extra": {}, "any": 1}`, []string{
+			`member not found: key "name" at ""`,
+			`type error: expected <number> or <integer>, got <string> at "/score"`,
+			`type error: expected <string>, got <integer> at "/tags/1"`,
+			`type error: expected <string>, got <null> at "/tags/2"`,
+			`member not found: key "active" at "/meta"`,
+			`type error: expected <array>, got <object> at "/extra"`,
+			`type error: expected <null>, got <integer> at "/any"`,
+		}},
+		{`[]`, []string{`type error: expected <object>, got <array> at ""`}},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			err := Validate(MustParse(test.input), template)
+			if test.expected == nil {
+				if err != nil {
+					t.Errorf("expected no error got %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected %T got %v", validationErr, err)
+			}
+			if len(validationErr.Errors) != len(test.expected) {
+				t.Fatalf("expected %v got %v", test.expected, validationErr.Errors)
+			}
+			for i, e := range validationErr.Errors {
+				if e.Error() != test.expected[i] {
+					t.Errorf("expected %v got %v", test.expected[i], e)
+				}
+			}
+		})
+	}
+
+	err := Validate(MustParse(`{"a": "x", "b": 1}`), MustParse(`{"a": 0, "c": 0}`))
+	if !errors.Is(err, ErrType) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v and %v got %v", ErrType, ErrNotFound, err)
+	}
+	if expected := `type error: expected <integer>, got <string> at "/a"; member not found: key "c" at ""`; err.Error() != expected {
+		t.Errorf("expected %v got %v", expected, err)
+	}
+}
+
+func TestValidateNested(t *testing.T) {
+	template := MustParse(`[{"points": [[0.0]]}]`)
+	err := Validate(MustParse(`[{"points": [[1, 2.5]]}, {"points": [[1], ["x"]]}]`), template)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 {
+		t.Fatalf("expected one error got %v", err)
+	}
+	if expected := `type error: expected <number> or <integer>, got <string> at "/1/points/1/0"`; err.Error() != expected {
+		t.Errorf("expected %v got %v", expected, err)
+	}
+}
@@ -0,0 +1,119 @@
+package json
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// Returned by a Walk callback to skip the children of the current
+	// array or object. Walk itself never returns it.
+	SkipChildren = errors.New("skip children")
+	// Returned by a Walk callback to stop the walk without an error.
+	// Walk itself never returns it.
+	SkipAll = errors.New("skip all")
+)
+
+// Visits every value in the tree depth first, including arrays and objects
+// themselves, calling fn with each value and its JSON Pointer. A value is
+// visited before its children, which are visited in order. If an object has
+// duplicate keys, each of them is visited, even though they share a pointer.
+//
+// If fn returns SkipChildren for an array or object, its children are not
+// visited. If fn returns SkipAll, the walk stops and Walk returns nil.
+// Any other error stops the walk and is returned by Walk.
+func (v *Value) Walk(fn func(path string, v *Value) error) error {
+	if err := v.walk("", fn); err != nil && !errors.Is(err, SkipAll) {
+		return err
+	}
+	return nil
+}
+
+// Visits a value and then its children, where path is the value's pointer.
+func (v *Value) walk(path string, fn func(path string, v *Value) error) error {
+	if err := fn(path, v); err != nil {
+		if errors.Is(err, SkipChildren) {
+			return nil
+		}
+		return err
+	}
+	switch v.jsonType {
+	case Array:
+		for i, val := range v.arrayValue {
+			if err := val.walk(path+"/"+strconv.Itoa(i), fn); err != nil {
+				return err
+			}
+		}
+	case Object:
+		for _, p := range v.objectValue {
+			if err := p.val.walk(path+formatPointer([]string{p.key}), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Counts the values in the tree, including the value itself and every
+// array, object and scalar inside it. Duplicate keys in an object are all
+// counted.
+func (v *Value) NodeCount() int {
+	n := 1
+	for _, val := range v.arrayValue {
+		n += val.NodeCount()
+	}
+	for _, p := range v.objectValue {
+		n += p.val.NodeCount()
+	}
+	return n
+}
+
+// Gets how deeply arrays and objects are nested in the tree. A scalar has a
+// depth of 0, an array or object holding only scalars has a depth of 1, and
+// each level of nesting inside that adds 1.
+func (v *Value) MaxDepth() int {
+	if v.jsonType != Array && v.jsonType != Object {
+		return 0
+	}
+	deepest := 0
+	for _, val := range v.arrayValue {
+		if d := val.MaxDepth(); d > deepest {
+			deepest = d
+		}
+	}
+	for _, p := range v.objectValue {
+		if d := p.val.MaxDepth(); d > deepest {
+			deepest = d
+		}
+	}
+	return deepest + 1
+}
+
+// Makes a copy of the tree with each value replaced by whatever fn returns
+// for it, such as to turn every number into a string or trim every string.
+// Children are transformed before the array or object holding them, so fn
+// sees containers with their new children already in place, and it is called
+// with each value's JSON Pointer like Walk. Return v to keep a value as it
+// is. A nil return is replaced with null. The original is left unchanged.
+func (v *Value) Transform(fn func(path string, v *Value) *Value) *Value {
+	return v.Clone().transform("", fn)
+}
+
+// Transforms a value's children in place and then the value itself, where
+// path is the value's pointer.
+func (v *Value) transform(path string, fn func(path string, v *Value) *Value) *Value {
+	switch v.jsonType {
+	case Array:
+		for i, val := range v.arrayValue {
+			v.arrayValue[i] = val.transform(path+"/"+strconv.Itoa(i), fn)
+		}
+	case Object:
+		for i, p := range v.objectValue {
+			v.objectValue[i].val = p.val.transform(path+formatPointer([]string{p.key}), fn)
+		}
+	}
+	if val := fn(path, v); val != nil {
+		return val
+	}
+	return NewNull()
+}
@@ -0,0 +1,129 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	val := MustParse(`{"a": [1, {"b": null}], "c/d": "e", "f": {}}`)
+	for _, test := range []struct {
+		name     string
+		fn       func(path string, v *Value) error
+		expected string
+		err      error
+	}{
+		{"all", func(path string, v *Value) error { return nil }, `|/a|/a/0|/a/1|/a/1/b|/c~1d|/f`, nil},
+		{"skip children", func(path string, v *Value) error {
+			if path == "/a" {
+				return SkipChildren
+			}
+			return nil
+		}, `|/a|/c~1d|/f`, nil},
+		{"skip all", func(path string, v *Value) error {
+			if path == "/a/1/b" {
+				return SkipAll
+			}
+			return nil
+		}, `|/a|/a/0|/a/1|/a/1/b`, nil},
+		{"error", func(path string, v *Value) error {
+			if v.Type() == Null {
+				return ErrType
+			}
+			return nil
+		}, `|/a|/a/0|/a/1|/a/1/b`, ErrType},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			paths := []string{}
+			err := val.Walk(func(path string, v *Value) error {
+				paths = append(paths, path)
+				if p, _ := val.Pointer(path); p != v {
+					t.Errorf("expected %v got %v", p, v)
+				}
+				return test.fn(path, v)
+			})
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v got %v", test.err, err)
+			}
+			if actual := strings.Join(paths, "|"); actual != test.expected {
+				t.Errorf("expected %v got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNodeCount(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		count int
+		depth int
+	}{
+		{`5`, 1, 0},
+		{`[]`, 1, 1},
+		{`{}`, 1, 1},
+		{`[1, 2, 3]`, 4, 1},
+		{`{"a": [1, {"b": null}], "c": "d"}`, 6, 3},
+		{`[[[[]]], 1]`, 5, 4},
+		{`{"a": 1, "a": 2}`, 3, 1},
+	} {
+		t.Run(test.input, func(t *testing.T) {
+			val := MustParse(test.input)
+			if actual := val.NodeCount(); actual != test.count {
+				t.Errorf("expected %v got %v", test.count, actual)
+			}
+			if actual := val.MaxDepth(); actual != test.depth {
+				t.Errorf("expected %v got %v", test.depth, actual)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	input := `{"a": [1, " x "], "b": {"c": 2.5, "d": null}, "e": [[]]}`
+	for _, test := range []struct {
+		name     string
+		fn       func(path string, v *Value) *Value
+		expected string
+	}{
+		{"identity", func(path string, v *Value) *Value { return v }, input},
+		{"numbers to strings", func(path string, v *Value) *Value {
+			if v.IsNumber() {
+				return NewString(v.String())
+			}
+			return v
+		}, `{"a": ["1", " x "], "b": {"c": "2.5", "d": null}, "e": [[]]}`},
+		{"trim strings", func(path string, v *Value) *Value {
+			if s, err := v.AsString(); err == nil {
+				return NewString(strings.TrimSpace(s))
+			}
+			return v
+		}, `{"a": [1, "x"], "b": {"c": 2.5, "d": null}, "e": [[]]}`},
+		{"by path", func(path string, v *Value) *Value {
+			if path == "/b" {
+				return NewInt(int64(v.NodeCount()))
+			}
+			return v
+		}, `{"a": [1, " x "], "b": 3, "e": [[]]}`},
+		{"children first", func(path string, v *Value) *Value {
+			if v.IsArray() && len(v.arrayValue) == 0 {
+				return nil
+			}
+			if v.IsArray() {
+				return NewArray(v.arrayValue[len(v.arrayValue)-1])
+			}
+			return v
+		}, `{"a": [" x "], "b": {"c": 2.5, "d": null}, "e": [null]}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			val := MustParse(input)
+			actual := val.Transform(test.fn)
+			if expected := MustParse(test.expected); !equals(expected, actual) {
+				t.Errorf("expected %v got %v", expected, actual)
+			}
+			if expected := MustParse(input); !equals(expected, val) {
+				t.Errorf("expected %v got %v", expected, val)
+			}
+		})
+	}
+}